@@ -120,6 +120,7 @@ const (
 const (
 	ConnectionsIncomingGaugeName = "gossip.connections.incoming"
 	ConnectionsOutgoingGaugeName = "gossip.connections.outgoing"
+	NodeCountGaugeName           = "gossip.node_count"
 	InfosSentRatesName           = "gossip.infos.sent"
 	InfosReceivedRatesName       = "gossip.infos.received"
 	BytesSentRatesName           = "gossip.bytes.sent"
@@ -184,6 +185,7 @@ type Gossip struct {
 	resolvers      []resolver.Resolver
 	resolversTried map[int]struct{} // Set of attempted resolver indexes
 	nodeDescs      map[roachpb.NodeID]*roachpb.NodeDescriptor
+	nodeCount      *metric.Gauge // Number of unexpired entries in nodeDescs
 
 	// Membership sets for resolvers and bootstrap addresses.
 	resolverAddrs  map[util.UnresolvedAddr]resolver.Resolver
@@ -204,6 +206,7 @@ func New(rpcContext *rpc.Context, grpcServer *grpc.Server, resolvers []resolver.
 		bootstrapInterval: defaultBootstrapInterval,
 		cullInterval:      defaultCullInterval,
 		nodeDescs:         map[roachpb.NodeID]*roachpb.NodeDescriptor{},
+		nodeCount:         registry.Gauge(NodeCountGaugeName),
 		resolverAddrs:     map[util.UnresolvedAddr]resolver.Resolver{},
 		bootstrapAddrs:    map[util.UnresolvedAddr]struct{}{},
 	}
@@ -520,6 +523,8 @@ func (g *Gossip) updateNodeAddress(_ string, content roachpb.Value) {
 
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	// The descriptor of a node seen before may have expired in the meantime.
+	defer g.updateNodeCountLocked()
 
 	// Skip if the node has already been seen.
 	if _, ok := g.nodeDescs[desc.NodeID]; ok {
@@ -527,6 +532,7 @@ func (g *Gossip) updateNodeAddress(_ string, content roachpb.Value) {
 	}
 
 	g.nodeDescs[desc.NodeID] = &desc
+	g.rpcContext.SetPeerNodeID(desc.Address.String(), desc.NodeID)
 
 	// Recompute max peers based on size of network and set the max
 	// sizes for incoming and outgoing node sets.
@@ -553,6 +559,19 @@ func (g *Gossip) updateNodeAddress(_ string, content roachpb.Value) {
 	}
 }
 
+// updateNodeCountLocked sets the node count to the number of nodes whose
+// descriptor is still gossiped. Descriptors whose gossip info has expired stay
+// in nodeDescs, but are not counted. The mutex is assumed held by the caller.
+func (g *Gossip) updateNodeCountLocked() {
+	var n int64
+	for nodeID := range g.nodeDescs {
+		if g.is.getInfo(MakeNodeIDKey(nodeID)) != nil {
+			n++
+		}
+	}
+	g.nodeCount.Update(n)
+}
+
 // getNodeDescriptorLocked looks up the descriptor of the node by ID. The mutex
 // is assumed held by the caller. This method is called externally via
 // GetNodeDescriptor and internally by getNodeIDAddressLocked.
@@ -933,6 +952,7 @@ func (g *Gossip) manage() {
 			case <-stallTicker.C:
 				g.mu.Lock()
 				g.maybeSignalStalledLocked()
+				g.updateNodeCountLocked()
 				g.mu.Unlock()
 			}
 		}
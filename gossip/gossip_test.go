@@ -57,6 +57,53 @@ func TestGossipInfoStore(t *testing.T) {
 	}
 }
 
+// TestGossipNodeCount verifies that the node count gauge tracks the node
+// descriptors known to gossip, including when they expire.
+func TestGossipNodeCount(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	rpcContext := rpc.NewContext(&base.Context{Insecure: true}, nil, stopper)
+	registry := metric.NewRegistry()
+	g := New(rpcContext, rpc.NewServer(rpcContext), nil, stopper, registry)
+	g.SetNodeID(roachpb.NodeID(1))
+
+	checkNodeCount := func(e int64) {
+		util.SucceedsSoon(t, func() error {
+			if a := registry.GetGauge(NodeCountGaugeName).Value(); a != e {
+				return errors.Errorf("expected %d nodes, got %d", e, a)
+			}
+			return nil
+		})
+	}
+	addNode := func(i int, ttl time.Duration) {
+		nodeID := roachpb.NodeID(i)
+		if err := g.AddInfoProto(MakeNodeIDKey(nodeID), &roachpb.NodeDescriptor{
+			NodeID:  nodeID,
+			Address: util.MakeUnresolvedAddr("tcp", "localhost:"+strconv.Itoa(26256+i)),
+		}, ttl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 1; i <= 2; i++ {
+		addNode(i, time.Hour)
+		checkNodeCount(int64(i))
+	}
+
+	// The descriptor of the third node expires quickly.
+	addNode(3, time.Second)
+	checkNodeCount(3)
+	util.SucceedsSoon(t, func() error {
+		g.mu.Lock()
+		g.updateNodeCountLocked()
+		g.mu.Unlock()
+		if a := registry.GetGauge(NodeCountGaugeName).Value(); a != 2 {
+			return errors.Errorf("expected 2 nodes, got %d", a)
+		}
+		return nil
+	})
+}
+
 func TestGossipGetNextBootstrapAddress(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	stopper := stop.NewStopper()
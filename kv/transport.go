@@ -178,6 +178,9 @@ func (gt *grpcTransport) SendNext(done chan BatchCall) {
 		return
 	}
 
+	// Local calls are not serialized, so only remote requests are counted.
+	gt.rpcContext.RecordBatchRequestSize(client.args.Size())
+
 	go func() {
 		ctx, cancel := gt.opts.contextWithTimeout()
 		defer cancel()
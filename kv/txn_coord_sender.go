@@ -112,6 +112,18 @@ type TxnMetrics struct {
 
 	// Restarts is the number of times we had to restart the transaction.
 	Restarts *metric.Histogram
+
+	// RestartsWriteTooOld and RestartsUncertainty count the transaction
+	// restarts caused by WriteTooOldErrors, which indicate contention on
+	// timestamps, and by ReadWithinUncertaintyIntervalErrors, which are a
+	// consequence of clock offsets between nodes.
+	RestartsWriteTooOld *metric.Counter
+	RestartsUncertainty *metric.Counter
+
+	// HeartbeatIntervals is the observed time between consecutive heartbeats
+	// of a transaction. Values well above the configured heartbeat interval
+	// indicate that the heartbeat goroutine is being starved.
+	HeartbeatIntervals metric.Histograms
 }
 
 const (
@@ -121,6 +133,11 @@ const (
 	abandonsPrefix   = "txn.abandons"
 	durationsPrefix  = "txn.durations"
 	restartsKey      = "txn.restarts"
+
+	heartbeatIntervalsPrefix = "kv.txn.heartbeat_interval_nanos"
+
+	restartsWriteTooOldKey = "kv.txn.restart_due_to_write_too_old"
+	restartsUncertaintyKey = "kv.txn.restart_uncertainty"
 )
 
 // NewTxnMetrics returns a new instance of txnMetrics that contains metrics which have
@@ -133,6 +150,11 @@ func NewTxnMetrics(registry *metric.Registry) *TxnMetrics {
 		Abandons:   registry.Rates(abandonsPrefix),
 		Durations:  registry.Latency(durationsPrefix),
 		Restarts:   registry.Histogram(restartsKey, 60*time.Second, 100, 3),
+
+		HeartbeatIntervals: registry.Latency(heartbeatIntervalsPrefix),
+
+		RestartsWriteTooOld: registry.Counter(restartsWriteTooOldKey),
+		RestartsUncertainty: registry.Counter(restartsUncertaintyKey),
 	}
 }
 
@@ -599,9 +621,13 @@ func (tc *TxnCoordSender) heartbeatLoop(ctx context.Context, txnID uuid.UUID) {
 		return
 	}
 	// Loop with ticker for periodic heartbeats.
+	lastHeartbeat := timeutil.Now()
 	for {
 		select {
 		case <-tickChan:
+			now := timeutil.Now()
+			tc.metrics.HeartbeatIntervals.RecordValue(now.Sub(lastHeartbeat).Nanoseconds())
+			lastHeartbeat = now
 			if !tc.heartbeat(ctx, txnID) {
 				return
 			}
@@ -780,6 +806,7 @@ func (tc *TxnCoordSender) updateState(
 		} else {
 			newTxn.Timestamp.Forward(restartTS)
 			newTxn.Restart(ba.UserPriority, newTxn.Priority, newTxn.Timestamp)
+			tc.metrics.RestartsUncertainty.Inc(1)
 		}
 	case *roachpb.TransactionAbortedError:
 		// Increase timestamp if applicable.
@@ -794,11 +821,17 @@ func (tc *TxnCoordSender) updateState(
 		newTxn.Timestamp.Forward(t.PusheeTxn.Timestamp)
 		newTxn.Restart(ba.UserPriority, t.PusheeTxn.Priority-1, newTxn.Timestamp)
 	case *roachpb.TransactionRetryError:
+		if newTxn.WriteTooOld {
+			// A write of the transaction encountered a newer value, which
+			// deferred the WriteTooOldError until the commit.
+			tc.metrics.RestartsWriteTooOld.Inc(1)
+		}
 		// Increase timestamp so on restart, we're ahead of any timestamp
 		// cache entries or newer versions which caused the restart.
 		newTxn.Restart(ba.UserPriority, pErr.GetTxn().Priority, newTxn.Timestamp)
 	case *roachpb.WriteTooOldError:
 		newTxn.Restart(ba.UserPriority, newTxn.Priority, t.ActualTimestamp)
+		tc.metrics.RestartsWriteTooOld.Inc(1)
 	case nil:
 		// Nothing to do here, avoid the default case.
 	default:
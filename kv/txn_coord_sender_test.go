@@ -325,6 +325,14 @@ func TestTxnCoordSenderHeartbeat(t *testing.T) {
 			return errors.Errorf("expected heartbeat")
 		})
 	}
+	// Every heartbeat of the heartbeat loop records the time since the
+	// previous one.
+	util.SucceedsSoon(t, func() error {
+		if a := sender.metrics.HeartbeatIntervals[metric.Scale1M].Current().TotalCount(); a < 3 {
+			return errors.Errorf("expected at least 3 heartbeat intervals, got %d", a)
+		}
+		return nil
+	})
 
 	// Sneakily send an ABORT right to DistSender (bypassing TxnCoordSender).
 	{
@@ -721,6 +729,8 @@ func TestTxnCoordSenderTxnUpdatedOnError(t *testing.T) {
 		expPri           int32
 		expTS, expOrigTS hlc.Timestamp
 		nodeSeen         bool
+		// The expected counts of write too old and uncertainty restarts.
+		expWriteTooOld, expUncertainty int64
 	}{
 		{
 			// No error, so nothing interesting either.
@@ -742,11 +752,23 @@ func TestTxnCoordSenderTxnUpdatedOnError(t *testing.T) {
 				pErr.OriginNode = nodeID
 				return pErr
 			}(),
-			expEpoch:  1,
-			expPri:    1,
-			expTS:     plus10,
-			expOrigTS: plus10,
-			nodeSeen:  true,
+			expEpoch:       1,
+			expPri:         1,
+			expTS:          plus10,
+			expOrigTS:      plus10,
+			nodeSeen:       true,
+			expUncertainty: 1,
+		},
+		{
+			// On write too old, new epoch begins just past the existing write.
+			pErr: roachpb.NewErrorWithTxn(
+				&roachpb.WriteTooOldError{ActualTimestamp: plus10},
+				&roachpb.Transaction{}),
+			expEpoch:       1,
+			expPri:         1,
+			expTS:          plus10,
+			expOrigTS:      plus10,
+			expWriteTooOld: 1,
 		},
 		{
 			// On abort, nothing changes but we get a new priority to use for
@@ -792,13 +814,14 @@ func TestTxnCoordSenderTxnUpdatedOnError(t *testing.T) {
 		clock := hlc.NewClock(manual.UnixNano)
 		clock.SetMaxOffset(20)
 
+		metrics := NewTxnMetrics(metric.NewRegistry())
 		ts := NewTxnCoordSender(senderFn(func(_ context.Context, ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
 			var reply *roachpb.BatchResponse
 			if test.pErr == nil {
 				reply = ba.CreateReply()
 			}
 			return reply, test.pErr
-		}), clock, false, tracing.NewTracer(), stopper, NewTxnMetrics(metric.NewRegistry()))
+		}), clock, false, tracing.NewTracer(), stopper, metrics)
 		db := client.NewDB(ts)
 		txn := client.NewTxn(context.Background(), *db)
 		txn.InternalSetPriority(1)
@@ -831,6 +854,14 @@ func TestTxnCoordSenderTxnUpdatedOnError(t *testing.T) {
 			t.Errorf("%d: expected nodeSeen=%t, but list of hosts is %v",
 				i, test.nodeSeen, ns)
 		}
+		if c := metrics.RestartsWriteTooOld.Count(); c != test.expWriteTooOld {
+			t.Errorf("%d: expected %d write too old restarts; got %d",
+				i, test.expWriteTooOld, c)
+		}
+		if c := metrics.RestartsUncertainty.Count(); c != test.expUncertainty {
+			t.Errorf("%d: expected %d uncertainty restarts; got %d",
+				i, test.expUncertainty, c)
+		}
 	}
 }
 
@@ -1357,6 +1388,46 @@ func TestTxnRestartCount(t *testing.T) {
 	checkTxnMetrics(t, sender, "restart txn", 0, 0, 0, 1, 1)
 }
 
+func TestTxnWriteTooOldRestartCount(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	_, sender, cleanupFn := setupMetricsTest(t)
+	defer cleanupFn()
+
+	key := []byte("key-write-too-old")
+	db := client.NewDB(sender)
+
+	// Start a transaction and do a GET. This forces a timestamp to be chosen for the transaction.
+	txn := client.NewTxn(context.Background(), *db)
+	if _, err := txn.Get("key-other"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Outside of the transaction, write the key the transaction is going to
+	// write. The transaction's write is then older than the existing value.
+	if err := db.Put(key, "newer"); err != nil {
+		t.Fatal(err)
+	}
+
+	// This put is pushed above the existing value and marks the transaction
+	// as WriteTooOld.
+	if err := txn.Put(key, "older"); err != nil {
+		t.Fatal(err)
+	}
+	if !txn.Proto.WriteTooOld {
+		t.Errorf("expected transaction to be marked WriteTooOld: %s", txn.Proto)
+	}
+
+	// Commit (should cause the write too old restart metric to increase).
+	err := txn.CommitOrCleanup()
+	assertTransactionRetryError(t, err)
+
+	teardownHeartbeats(sender)
+	checkTxnMetrics(t, sender, "write too old restart txn", 0, 0, 0, 1, 1)
+	if c := sender.metrics.RestartsWriteTooOld.Count(); c != 1 {
+		t.Errorf("expected 1 write too old restart, got %d", c)
+	}
+}
+
 func TestTxnDurations(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	manual, sender, cleanupFn := setupMetricsTest(t)
@@ -18,6 +18,9 @@ package rpc
 
 import (
 	"math"
+	"net"
+	"os"
+	"syscall"
 	"time"
 
 	"github.com/rubyist/circuitbreaker"
@@ -31,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/util/grpcutil"
 	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/stop"
 	"github.com/cockroachdb/cockroach/util/syncutil"
 	"github.com/cockroachdb/cockroach/util/timeutil"
@@ -47,6 +51,44 @@ const (
 	maximumPingDurationMult = 2
 )
 
+// MetricConnectionRefusedName is the name of the counter of connection
+// attempts to remote nodes which were refused, labeled by the ID of the peer
+// node. Attempts to addresses whose node ID is not known (yet) are counted
+// under unknownPeerLabel, which keeps the set of labels bounded by the number
+// of nodes.
+const MetricConnectionRefusedName = "net.rpc.connection_refused_count"
+
+const unknownPeerLabel = "other"
+
+// MetricKeepalivePingsName and MetricKeepalivePingTimeoutsName are the names
+// of the counters of heartbeats sent on client connections and of those which
+// timed out. gRPC keepalives are not enabled; the heartbeats keep the
+// connections alive instead.
+const (
+	MetricKeepalivePingsName        = "net.grpc.keepalive_ping_count"
+	MetricKeepalivePingTimeoutsName = "net.grpc.keepalive_ping_timeout_count"
+)
+
+// MetricStreamMessagesSentName and MetricStreamMessagesReceivedName are the
+// names of the counters of messages exchanged over streaming RPCs, labeled by
+// the type of stream.
+const (
+	MetricStreamMessagesSentName     = "net.rpc.stream.messages_sent"
+	MetricStreamMessagesReceivedName = "net.rpc.stream.messages_received"
+)
+
+// MetricConnectionPoolSizeName is the name of the gauge of open client
+// connections to remote nodes. The context keeps at most one connection per
+// remote address.
+const MetricConnectionPoolSizeName = "net.rpc.connection_pool_size"
+
+// MetricBatchRequestSizeName is the prefix of the names of the histograms of
+// the serialized sizes of batch requests sent to remote nodes.
+const MetricBatchRequestSizeName = "net.rpc.batch.request_size_bytes"
+
+// maxBatchRequestSize is the gRPC message size limit set by NewServer.
+const maxBatchRequestSize = math.MaxInt32
+
 // NewServer is a thin wrapper around grpc.NewServer that registers a heartbeat
 // service.
 func NewServer(ctx *Context) *grpc.Server {
@@ -88,10 +130,25 @@ type Context struct {
 
 	localInternalServer roachpb.InternalServer
 
+	connRefused            *metric.LabeledCounter
+	streamMessagesSent     *metric.LabeledCounter
+	streamMessagesReceived *metric.LabeledCounter
+	batchRequestSize       metric.Histograms
+	connPoolSize           *metric.Gauge
+	keepalivePings         *metric.Counter
+	keepalivePingTimeouts  *metric.Counter
+
 	conns struct {
 		syncutil.Mutex
 		cache map[string]connMeta
 	}
+
+	// peerNodeIDs maps the addresses of remote nodes to their node IDs, as
+	// far as they are known.
+	peerNodeIDs struct {
+		syncutil.Mutex
+		m map[string]roachpb.NodeID
+	}
 }
 
 // NewContext creates an rpc Context with the supplied values.
@@ -112,6 +169,17 @@ func NewContext(baseCtx *base.Context, hlcClock *hlc.Clock, stopper *stop.Stoppe
 	ctx.HeartbeatInterval = defaultHeartbeatInterval
 	ctx.HeartbeatTimeout = 2 * defaultHeartbeatInterval
 	ctx.conns.cache = make(map[string]connMeta)
+	ctx.peerNodeIDs.m = make(map[string]roachpb.NodeID)
+	ctx.connRefused = metric.NewLabeledCounter("peer")
+	ctx.streamMessagesSent = metric.NewLabeledCounter("stream")
+	ctx.streamMessagesReceived = metric.NewLabeledCounter("stream")
+	ctx.batchRequestSize = make(metric.Histograms)
+	for _, scale := range metric.DefaultTimeScales {
+		ctx.batchRequestSize[scale] = metric.NewHistogram(scale.Duration(), maxBatchRequestSize, 1)
+	}
+	ctx.connPoolSize = metric.NewGauge()
+	ctx.keepalivePings = metric.NewCounter()
+	ctx.keepalivePingTimeouts = metric.NewCounter()
 
 	stopper.RunWorker(func() {
 		<-stopper.ShouldQuiesce()
@@ -150,6 +218,7 @@ func (ctx *Context) removeConn(key string, conn *grpc.ClientConn) {
 		}
 	}
 	delete(ctx.conns.cache, key)
+	ctx.connPoolSize.Update(int64(len(ctx.conns.cache)))
 }
 
 // GRPCDial calls grpc.Dial with the options appropriate for the context.
@@ -166,8 +235,8 @@ func (ctx *Context) GRPCDial(target string, opts ...grpc.DialOption) (*grpc.Clie
 		return nil, err
 	}
 
-	dialOpts := make([]grpc.DialOption, 0, 1+len(opts))
-	dialOpts = append(dialOpts, dialOpt)
+	dialOpts := make([]grpc.DialOption, 0, 2+len(opts))
+	dialOpts = append(dialOpts, dialOpt, grpc.WithDialer(ctx.dial))
 	dialOpts = append(dialOpts, opts...)
 
 	if log.V(1) {
@@ -176,6 +245,7 @@ func (ctx *Context) GRPCDial(target string, opts ...grpc.DialOption) (*grpc.Clie
 	conn, err := grpc.Dial(target, dialOpts...)
 	if err == nil {
 		ctx.conns.cache[target] = connMeta{conn: conn}
+		ctx.connPoolSize.Update(int64(len(ctx.conns.cache)))
 
 		if ctx.Stopper.RunTask(func() {
 			ctx.Stopper.RunWorker(func() {
@@ -194,6 +264,77 @@ func (ctx *Context) GRPCDial(target string, opts ...grpc.DialOption) (*grpc.Clie
 	return conn, err
 }
 
+// dial establishes the network connections of client connections created by
+// GRPCDial, counting the attempts which are refused by the peer.
+func (ctx *Context) dial(addr string, timeout time.Duration) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil && isConnectionRefused(err) {
+		ctx.connRefused.WithLabelValues(ctx.peerLabel(addr)).Inc(1)
+	}
+	return conn, err
+}
+
+// isConnectionRefused returns whether err is the error returned by a dial
+// which the peer refused.
+func isConnectionRefused(err error) bool {
+	if opErr, ok := err.(*net.OpError); ok {
+		if sysErr, ok := opErr.Err.(*os.SyscallError); ok {
+			return sysErr.Err == syscall.ECONNREFUSED
+		}
+	}
+	return false
+}
+
+// SetPeerNodeID records the node ID of the node at the given address. It is
+// used to label the per-peer connection metrics.
+func (ctx *Context) SetPeerNodeID(addr string, nodeID roachpb.NodeID) {
+	ctx.peerNodeIDs.Lock()
+	defer ctx.peerNodeIDs.Unlock()
+	ctx.peerNodeIDs.m[addr] = nodeID
+}
+
+// peerLabel returns the label of per-peer connection metrics for the given
+// address.
+func (ctx *Context) peerLabel(addr string) string {
+	ctx.peerNodeIDs.Lock()
+	defer ctx.peerNodeIDs.Unlock()
+	if nodeID, ok := ctx.peerNodeIDs.m[addr]; ok {
+		return nodeID.String()
+	}
+	return unknownPeerLabel
+}
+
+// RegisterMetrics adds the connection metrics of the context to a registry.
+func (ctx *Context) RegisterMetrics(reg *metric.Registry) {
+	reg.MustAdd(MetricConnectionRefusedName, ctx.connRefused)
+	reg.MustAdd(MetricStreamMessagesSentName, ctx.streamMessagesSent)
+	reg.MustAdd(MetricStreamMessagesReceivedName, ctx.streamMessagesReceived)
+	for scale, h := range ctx.batchRequestSize {
+		reg.MustAdd(MetricBatchRequestSizeName+"-"+scale.Name(), h)
+	}
+	reg.MustAdd(MetricConnectionPoolSizeName, ctx.connPoolSize)
+	reg.MustAdd(MetricKeepalivePingsName, ctx.keepalivePings)
+	reg.MustAdd(MetricKeepalivePingTimeoutsName, ctx.keepalivePingTimeouts)
+}
+
+// StreamMessagesSent returns the counter of messages sent over streams of the
+// given type, e.g. "raft".
+func (ctx *Context) StreamMessagesSent(stream string) *metric.Counter {
+	return ctx.streamMessagesSent.WithLabelValues(stream)
+}
+
+// StreamMessagesReceived returns the counter of messages received over streams
+// of the given type, e.g. "raft".
+func (ctx *Context) StreamMessagesReceived(stream string) *metric.Counter {
+	return ctx.streamMessagesReceived.WithLabelValues(stream)
+}
+
+// RecordBatchRequestSize records the serialized size in bytes of a batch
+// request sent to a remote node.
+func (ctx *Context) RecordBatchRequestSize(size int) {
+	ctx.batchRequestSize.RecordValue(int64(size))
+}
+
 // GRPCDialOption returns the GRPC dialing option appropriate for the context.
 func (ctx *Context) GRPCDialOption() (grpc.DialOption, error) {
 	var dialOpt grpc.DialOption
@@ -257,6 +398,7 @@ func (ctx *Context) runHeartbeat(cc *grpc.ClientConn, remoteAddr string) error {
 
 		sendTime := ctx.localClock.PhysicalTime()
 		response, err := ctx.heartbeat(heartbeatClient, request)
+		ctx.keepalivePings.Inc(1)
 		ctx.setConnHealthy(remoteAddr, err == nil)
 		if err == nil {
 			receiveTime := ctx.localClock.PhysicalTime()
@@ -286,6 +428,7 @@ func (ctx *Context) runHeartbeat(cc *grpc.ClientConn, remoteAddr string) error {
 		// If the heartbeat timed out, run the next one immediately. Otherwise,
 		// wait out the heartbeat interval on the next iteration.
 		if grpc.Code(err) == codes.DeadlineExceeded {
+			ctx.keepalivePingTimeouts.Inc(1)
 			nextHeartbeat = 0
 		} else {
 			nextHeartbeat = ctx.HeartbeatInterval
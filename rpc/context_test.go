@@ -18,6 +18,7 @@ package rpc
 
 import (
 	"net"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -30,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/leaktest"
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/netutil"
 	"github.com/cockroachdb/cockroach/util/stop"
 	"github.com/cockroachdb/cockroach/util/syncutil"
@@ -538,3 +540,164 @@ func TestRemoteOffsetUnhealthy(t *testing.T) {
 		}
 	}
 }
+
+func TestConnectionRefusedCount(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	clock := hlc.NewClock(time.Unix(0, 1).UnixNano)
+	clientCtx := newNodeTestContext(clock, stopper)
+
+	// unusedAddr returns an address which nobody listens on, so that
+	// connection attempts to it are refused.
+	unusedAddr := func() string {
+		ln, err := net.Listen(util.TestAddr.Network(), util.TestAddr.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr := ln.Addr().String()
+		if err := ln.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return addr
+	}
+
+	// The node ID of the first address is not known.
+	if _, err := clientCtx.GRPCDial(unusedAddr()); err != nil {
+		t.Fatal(err)
+	}
+	// The node ID of the second one is.
+	knownAddr := unusedAddr()
+	clientCtx.SetPeerNodeID(knownAddr, 2)
+	if _, err := clientCtx.GRPCDial(knownAddr); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, peer := range []string{unknownPeerLabel, "2"} {
+		util.SucceedsSoon(t, func() error {
+			if c := clientCtx.connRefused.WithLabelValues(peer).Count(); c == 0 {
+				return errors.Errorf("no refused connections counted for peer %s", peer)
+			}
+			return nil
+		})
+	}
+}
+
+func TestBatchRequestSizeMetric(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	ctx := newNodeTestContext(hlc.NewClock(time.Unix(0, 1).UnixNano), stopper)
+	reg := metric.NewRegistry()
+	ctx.RegisterMetrics(reg)
+
+	ctx.RecordBatchRequestSize(100)
+	ctx.RecordBatchRequestSize(300)
+
+	// There is one histogram per time scale, and each of them has seen both
+	// requests.
+	var names []string
+	reg.Each(func(name string, v interface{}) {
+		h, ok := v.(*metric.Histogram)
+		if !ok || !strings.HasPrefix(name, MetricBatchRequestSizeName) {
+			return
+		}
+		names = append(names, name)
+		if c := h.Current().TotalCount(); c != 2 {
+			t.Errorf("%s: expected 2 recorded requests, got %d", name, c)
+		}
+		if max := h.Current().Max(); max < 300 {
+			t.Errorf("%s: expected a maximum of at least 300, got %d", name, max)
+		}
+	})
+	if len(names) != len(metric.DefaultTimeScales) {
+		t.Errorf("expected %d histograms, got %v", len(metric.DefaultTimeScales), names)
+	}
+	for _, scale := range metric.DefaultTimeScales {
+		if name := MetricBatchRequestSizeName + "-" + scale.Name(); reg.GetHistogram(name) == nil {
+			t.Errorf("histogram %s not registered, got %v", name, names)
+		}
+	}
+}
+
+func TestConnectionPoolSizeMetric(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	clock := hlc.NewClock(time.Unix(0, 1).UnixNano)
+	serverCtx := newNodeTestContext(clock, stopper)
+	s, ln := newTestServer(t, serverCtx, true)
+	remoteAddr := ln.Addr().String()
+
+	RegisterHeartbeatServer(s, &HeartbeatService{
+		clock:              clock,
+		remoteClockMonitor: serverCtx.RemoteClocks,
+	})
+
+	clientCtx := newNodeTestContext(clock, stopper)
+	reg := metric.NewRegistry()
+	clientCtx.RegisterMetrics(reg)
+	poolSize := reg.GetGauge(MetricConnectionPoolSizeName)
+
+	if v := poolSize.Value(); v != 0 {
+		t.Fatalf("expected an empty connection pool, got %d connections", v)
+	}
+	// Dialing the same address twice reuses the pooled connection.
+	for i := 0; i < 2; i++ {
+		if _, err := clientCtx.GRPCDial(remoteAddr); err != nil {
+			t.Fatal(err)
+		}
+		if v := poolSize.Value(); v != 1 {
+			t.Errorf("expected 1 pooled connection, got %d", v)
+		}
+	}
+}
+
+func TestKeepalivePingMetrics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+
+	clock := hlc.NewClock(time.Unix(0, 1).UnixNano)
+	serverCtx := newNodeTestContext(clock, stopper)
+	s, ln := newTestServer(t, serverCtx, true)
+	remoteAddr := ln.Addr().String()
+
+	heartbeat := &ManualHeartbeatService{
+		clock:              clock,
+		remoteClockMonitor: serverCtx.RemoteClocks,
+		ready:              make(chan struct{}),
+		stopper:            stopper,
+	}
+	RegisterHeartbeatServer(s, heartbeat)
+
+	clientCtx := newNodeTestContext(clock, stopper)
+	clientCtx.HeartbeatTimeout = 2 * clientCtx.HeartbeatInterval
+	reg := metric.NewRegistry()
+	clientCtx.RegisterMetrics(reg)
+	pings := reg.GetCounter(MetricKeepalivePingsName)
+	timeouts := reg.GetCounter(MetricKeepalivePingTimeoutsName)
+
+	if _, err := clientCtx.GRPCDial(remoteAddr); err != nil {
+		t.Fatal(err)
+	}
+	heartbeat.ready <- struct{}{} // Allow one heartbeat to succeed.
+
+	// All later heartbeats time out, and are counted as pings as well.
+	util.SucceedsSoon(t, func() error {
+		if c := timeouts.Count(); c < 1 {
+			return errors.Errorf("expected a ping timeout, got %d", c)
+		}
+		if p, c := pings.Count(), timeouts.Count(); p <= c {
+			return errors.Errorf("expected more pings than the %d timeouts, got %d", c, p)
+		}
+		return nil
+	})
+}
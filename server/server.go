@@ -99,6 +99,9 @@ type Server struct {
 	stopper       *stop.Stopper
 	sqlExecutor   *sql.Executor
 	leaseMgr      *sql.LeaseManager
+	// schemaChangeMetrics is shared by the sqlExecutor and the schema change
+	// manager.
+	schemaChangeMetrics *sql.SchemaChangeMetrics
 }
 
 // NewServer creates a Server from a server.Context.
@@ -183,22 +186,33 @@ func NewServer(ctx Context, stopper *stop.Stopper) (*Server, error) {
 	s.leaseMgr.RefreshLeases(s.stopper, s.db, s.gossip)
 
 	// Set up the DistSQL server
+	rowsReadCount := s.registry.Counter(sql.MetricRowsReadName)
 	distSQLCtx := distsql.ServerContext{
-		Context:    context.Background(),
-		DB:         s.db,
-		RPCContext: s.rpcContext,
+		Context:       context.Background(),
+		DB:            s.db,
+		RPCContext:    s.rpcContext,
+		RowsReadCount: rowsReadCount,
+		FlowsActive:   s.registry.Gauge(distsql.MetricFlowsActiveName),
+		ErrorsCount:   s.registry.LabeledCounter(distsql.MetricErrorsName, "class"),
+		PlanSizes: s.registry.Histograms(
+			distsql.MetricPlanSizeName, distsql.MetricPlanSizeMaxVal, 1),
 	}
 	s.distSQLServer = distsql.NewServer(distSQLCtx)
 	distsql.RegisterDistSQLServer(s.grpc, s.distSQLServer)
 
 	// Set up Executor
+	s.schemaChangeMetrics = sql.NewSchemaChangeMetrics(s.registry)
 	eCtx := sql.ExecutorContext{
-		Context:      context.Background(),
-		DB:           s.db,
-		Gossip:       s.gossip,
-		LeaseManager: s.leaseMgr,
-		Clock:        s.clock,
-		DistSQLSrv:   s.distSQLServer,
+		Context:             context.Background(),
+		DB:                  s.db,
+		Gossip:              s.gossip,
+		LeaseManager:        s.leaseMgr,
+		Clock:               s.clock,
+		DistSQLSrv:          s.distSQLServer,
+		SchemaChangeMetrics: s.schemaChangeMetrics,
+		RowsReadCount:       rowsReadCount,
+
+		IndexJoinRowsFetchedCount: s.registry.Counter(sql.MetricIndexJoinRowsFetchedName),
 	}
 	if ctx.TestingKnobs.SQLExecutor != nil {
 		eCtx.TestingKnobs = ctx.TestingKnobs.SQLExecutor.(*sql.ExecutorTestingKnobs)
@@ -237,6 +251,8 @@ func NewServer(ctx Context, stopper *stop.Stopper) (*Server, error) {
 
 	s.recorder = status.NewMetricsRecorder(s.clock)
 	s.rpcContext.RemoteClocks.RegisterMetrics(s.registry)
+	s.rpcContext.RegisterMetrics(s.registry)
+	s.raftTransport.RegisterMetrics(s.registry)
 	s.runtime = status.MakeRuntimeStatSampler(s.clock, s.registry)
 
 	s.node = NewNode(nCtx, s.recorder, s.registry, s.stopper, txnMetrics, sql.MakeEventLogger(s.leaseMgr))
@@ -424,7 +440,9 @@ func (s *Server) Start() error {
 	if s.ctx.TestingKnobs.SQLSchemaChangeManager != nil {
 		testingKnobs = s.ctx.TestingKnobs.SQLSchemaChangeManager.(*sql.SchemaChangeManagerTestingKnobs)
 	}
-	sql.NewSchemaChangeManager(testingKnobs, *s.db, s.gossip, s.leaseMgr).Start(s.stopper)
+	sql.NewSchemaChangeManager(
+		testingKnobs, *s.db, s.gossip, s.leaseMgr, s.schemaChangeMetrics,
+	).Start(s.stopper)
 
 	log.Infof(context.TODO(), "starting %s server at %s", s.ctx.HTTPRequestScheme(), unresolvedHTTPAddr)
 	log.Infof(context.TODO(), "starting grpc/postgres server at %s", unresolvedAddr)
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 
 	"golang.org/x/net/context"
 
@@ -321,6 +322,14 @@ func eachRecordableValue(reg *metric.Registry, fn func(string, float64)) {
 			for _, pt := range recordHistogramQuantiles {
 				fn(name+pt.suffix, float64(curr.ValueAtQuantile(pt.quantile)))
 			}
+		} else if lc, ok := mtr.(*metric.LabeledCounter); ok {
+			lc.EachLabeled(func(values []string, c *metric.Counter) {
+				fn(name+"."+strings.Join(values, "."), float64(c.Count()))
+			})
+		} else if lg, ok := mtr.(*metric.LabeledGauge); ok {
+			lg.EachLabeled(func(values []string, g *metric.Gauge) {
+				fn(name+"."+strings.Join(values, "."), float64(g.Value()))
+			})
 		} else {
 			val, err := extractValue(mtr)
 			if err != nil {
@@ -321,6 +321,11 @@ func (ts *TestServer) GetHTTPClient() (http.Client, error) {
 	return ts.Ctx.GetHTTPClient()
 }
 
+// Registry returns the server's metric registry.
+func (ts *TestServer) Registry() *metric.Registry {
+	return ts.registry
+}
+
 // MustGetSQLCounter implements TestServerInterface.
 func (ts *TestServer) MustGetSQLCounter(name string) int64 {
 	var c int64
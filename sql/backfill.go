@@ -258,7 +258,11 @@ func (sc *SchemaChanger) truncateAndBackfillColumnsChunk(
 ) (roachpb.Key, bool, error) {
 	var curIndexKey roachpb.Key
 	done := false
+	// rowsProcessed is only recorded once the transaction commits, so that
+	// retried chunks are not counted twice.
+	var rowsProcessed int
 	err := sc.db.Txn(context.TODO(), func(txn *client.Txn) error {
+		rowsProcessed = 0
 		tableDesc, err := sqlbase.GetTableDescFromID(txn, sc.tableID)
 		if err != nil {
 			return err
@@ -368,8 +372,12 @@ func (sc *SchemaChanger) truncateAndBackfillColumnsChunk(
 		if err := txn.Run(writeBatch); err != nil {
 			return convertBackfillError(tableDesc, writeBatch)
 		}
+		rowsProcessed = i
 		return nil
 	})
+	if err == nil {
+		sc.metrics.recordBackfillRows(backfillTypeColumn, rowsProcessed)
+	}
 	return curIndexKey.PrefixEnd(), done, err
 }
 
@@ -455,7 +463,9 @@ func (sc *SchemaChanger) backfillIndexesChunk(
 ) (roachpb.Key, bool, error) {
 	var nextKey roachpb.Key
 	done := false
+	var rowsProcessed int
 	err := sc.db.Txn(context.TODO(), func(txn *client.Txn) error {
+		rowsProcessed = 0
 		tableDesc, err := sqlbase.GetTableDescFromID(txn, sc.tableID)
 		if err != nil {
 			return err
@@ -529,6 +539,7 @@ func (sc *SchemaChanger) backfillIndexesChunk(
 		if err := txn.Run(b); err != nil {
 			return convertBackfillError(tableDesc, b)
 		}
+		rowsProcessed = numRows
 		// Have we processed all the table rows?
 		if numRows < IndexBackfillChunkSize {
 			done = true
@@ -538,5 +549,8 @@ func (sc *SchemaChanger) backfillIndexesChunk(
 		nextKey = scan.fetcher.Key()
 		return nil
 	})
+	if err == nil {
+		sc.metrics.recordBackfillRows(backfillTypeIndex, rowsProcessed)
+	}
 	return nextKey, done, err
 }
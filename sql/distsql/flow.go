@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/rpc"
 	"github.com/cockroachdb/cockroach/sql/parser"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/uuid"
 	"github.com/pkg/errors"
 )
@@ -50,6 +51,9 @@ type FlowCtx struct {
 	evalCtx *parser.EvalContext
 	rpcCtx  *rpc.Context
 	txn     *client.Txn
+
+	// rowsReadCount, if set, is incremented for every row scanned by a reader.
+	rowsReadCount *metric.Counter
 }
 
 type flowStatus int
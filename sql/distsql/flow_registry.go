@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/syncutil"
 )
 
@@ -43,11 +44,16 @@ type flowEntry struct {
 type flowRegistry struct {
 	mu    syncutil.Mutex
 	flows map[FlowID]*flowEntry
+
+	// flowsActive, if set, tracks the number of registered flows. It is
+	// updated while holding mu.
+	flowsActive *metric.Gauge
 }
 
-func makeFlowRegistry() *flowRegistry {
+func makeFlowRegistry(flowsActive *metric.Gauge) *flowRegistry {
 	fr := &flowRegistry{
-		flows: make(map[FlowID]*flowEntry),
+		flows:       make(map[FlowID]*flowEntry),
+		flowsActive: flowsActive,
 	}
 	return fr
 }
@@ -93,6 +99,9 @@ func (fr *flowRegistry) RegisterFlow(id FlowID, f *Flow) {
 	// Take a reference that will be removed by UnregisterFlow.
 	entry.refCount++
 	entry.flow = f
+	if fr.flowsActive != nil {
+		fr.flowsActive.Inc(1)
+	}
 	// If there are any waiters, wake them up by closing waitCh.
 	if entry.waitCh != nil {
 		close(entry.waitCh)
@@ -104,6 +113,9 @@ func (fr *flowRegistry) RegisterFlow(id FlowID, f *Flow) {
 func (fr *flowRegistry) UnregisterFlow(id FlowID) {
 	fr.mu.Lock()
 	fr.releaseEntryLocked(id)
+	if fr.flowsActive != nil {
+		fr.flowsActive.Dec(1)
+	}
 	fr.mu.Unlock()
 }
 
@@ -21,11 +21,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/uuid"
 )
 
 func TestFlowRegistry(t *testing.T) {
-	reg := makeFlowRegistry()
+	reg := makeFlowRegistry(nil)
 
 	id1 := FlowID{uuid.MakeV4()}
 	f1 := &Flow{}
@@ -121,3 +122,25 @@ func TestFlowRegistry(t *testing.T) {
 	reg.RegisterFlow(id3, f3)
 	wg2.Wait()
 }
+
+func TestFlowRegistryFlowsActive(t *testing.T) {
+	flowsActive := metric.NewGauge()
+	reg := makeFlowRegistry(flowsActive)
+
+	id1 := FlowID{uuid.MakeV4()}
+	id2 := FlowID{uuid.MakeV4()}
+
+	reg.RegisterFlow(id1, &Flow{})
+	reg.RegisterFlow(id2, &Flow{})
+	if v := flowsActive.Value(); v != 2 {
+		t.Errorf("expected 2 active flows, got %d", v)
+	}
+	reg.UnregisterFlow(id1)
+	if v := flowsActive.Value(); v != 1 {
+		t.Errorf("expected 1 active flow, got %d", v)
+	}
+	reg.UnregisterFlow(id2)
+	if v := flowsActive.Value(); v != 0 {
+		t.Errorf("expected no active flows, got %d", v)
+	}
+}
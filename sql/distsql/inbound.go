@@ -20,8 +20,12 @@ import (
 	"io"
 
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
 )
 
+// flowStreamName labels DistSQL flow streams in the RPC stream metrics.
+const flowStreamName = "distsql"
+
 // ProcessInboundStream receives rows from a DistSQL_FlowStreamServer and sends
 // them to a RowReceiver. Optionally processes an initial StreamMessage that was
 // already received (because the first message contains the flow and stream IDs,
@@ -46,6 +50,11 @@ func ProcessInboundStream(
 		return stream.SendAndClose(&SimpleResponse{})
 	}
 
+	var received *metric.Counter
+	if flowCtx.rpcCtx != nil {
+		received = flowCtx.rpcCtx.StreamMessagesReceived(flowStreamName)
+	}
+
 	var sd StreamDecoder
 	for {
 		var msg *StreamMessage
@@ -65,6 +74,9 @@ func ProcessInboundStream(
 				return finish(nil)
 			}
 		}
+		if received != nil {
+			received.Inc(1)
+		}
 		err := sd.AddMessage(msg)
 		if err != nil {
 			return finish(err)
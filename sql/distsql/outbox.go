@@ -24,6 +24,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
 )
 
 const outboxBufRows = 16
@@ -53,6 +54,10 @@ type outbox struct {
 	// numRows is the number of rows that have been accumulated in the encoder.
 	numRows int
 
+	// messagesSent counts the messages sent on the stream. It is nil if the
+	// flow context has no rpc context.
+	messagesSent *metric.Counter
+
 	err error
 	wg  *sync.WaitGroup
 }
@@ -116,12 +121,18 @@ func (m *outbox) flush(last bool, err error) error {
 	if sendErr != nil {
 		return sendErr
 	}
+	if m.messagesSent != nil {
+		m.messagesSent.Inc(1)
+	}
 
 	m.numRows = 0
 	return nil
 }
 
 func (m *outbox) mainLoop() error {
+	if m.flowCtx.rpcCtx != nil {
+		m.messagesSent = m.flowCtx.rpcCtx.StreamMessagesSent(flowStreamName)
+	}
 	if m.simpleFlowStream == nil {
 		conn, err := m.flowCtx.rpcCtx.GRPCDial(m.addr)
 		if err != nil {
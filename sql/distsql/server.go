@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/rpc"
 	"github.com/cockroachdb/cockroach/sql/parser"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/pkg/errors"
 )
 
@@ -36,8 +37,48 @@ type ServerContext struct {
 	Context    context.Context
 	DB         *client.DB
 	RPCContext *rpc.Context
+
+	// RowsReadCount counts the rows scanned by table and join readers, before
+	// any filter is applied. It can be nil.
+	RowsReadCount *metric.Counter
+
+	// FlowsActive tracks the number of flows registered with this server,
+	// i.e. all flows other than those run synchronously. It can be nil.
+	FlowsActive *metric.Gauge
+
+	// ErrorsCount counts the errors returned by this server, labeled by the
+	// class of error (see the errClass constants). It can be nil.
+	ErrorsCount *metric.LabeledCounter
+
+	// PlanSizes records the serialized size of the flow specs received over
+	// the SetupFlow and RunSimpleFlow RPCs. It can be nil.
+	PlanSizes metric.Histograms
 }
 
+// MetricFlowsActiveName is the name of the gauge of active flows.
+const MetricFlowsActiveName = "sql.distsql.flows_active"
+
+// MetricErrorsName is the name of the counter of DistSQL errors.
+const MetricErrorsName = "sql.distsql.errors_total"
+
+// MetricPlanSizeName is the name of the histograms of serialized flow spec
+// sizes.
+const MetricPlanSizeName = "sql.distsql.plan_diagram_size_bytes"
+
+// MetricPlanSizeMaxVal is far above the size of any flow spec we plan today.
+const MetricPlanSizeMaxVal = 64 << 20 // 64 MB
+
+// Error classes used as the label of ErrorsCount.
+const (
+	// errClassSetup is used for errors setting up a flow.
+	errClassSetup = "setup"
+	// errClassStream is used for errors receiving an inbound stream, including
+	// streams for flows which could not be found.
+	errClassStream = "stream"
+	// errClassOutbox is used for errors sending the results of a simple flow.
+	errClassOutbox = "outbox"
+)
+
 // ServerImpl implements the server for the distributed SQL APIs.
 type ServerImpl struct {
 	ServerContext
@@ -58,11 +99,18 @@ func NewServer(ctx ServerContext) *ServerImpl {
 		evalCtx: parser.EvalContext{
 			ReCache: parser.NewRegexpCache(512),
 		},
-		flowRegistry: makeFlowRegistry(),
+		flowRegistry: makeFlowRegistry(ctx.FlowsActive),
 	}
 	return ds
 }
 
+// countError increments the error counter for the given class.
+func (ds *ServerImpl) countError(class string) {
+	if ds.ErrorsCount != nil {
+		ds.ErrorsCount.WithLabelValues(class).Inc(1)
+	}
+}
+
 // SetNodeID sets the NodeID for the server.
 func (ds *ServerImpl) SetNodeID(nodeID roachpb.NodeID) {
 	ds.ServerContext.Context = log.WithLogTagInt(ds.ServerContext.Context, "node", int(nodeID))
@@ -90,12 +138,15 @@ func (ds *ServerImpl) SetupSimpleFlow(
 		evalCtx: &ds.evalCtx,
 		rpcCtx:  ds.RPCContext,
 		txn:     txn,
+
+		rowsReadCount: ds.RowsReadCount,
 	}
 
 	f := newFlow(flowCtx, ds.flowRegistry, output)
 	err := f.setupFlow(&req.Flow)
 	if err != nil {
 		log.Errorf(ds.Context, err.Error(), "", err)
+		ds.countError(errClassSetup)
 		return nil, err
 	}
 	return f, nil
@@ -106,6 +157,7 @@ func (ds *ServerImpl) RunSimpleFlow(
 	req *SetupFlowRequest, stream DistSQL_RunSimpleFlowServer,
 ) error {
 	ctx := ds.ServerContext.Context
+	ds.PlanSizes.RecordValue(int64(req.Flow.Size()))
 
 	// Set up the outgoing mailbox for the stream.
 	mbox := newOutboxSimpleFlowStream(stream)
@@ -122,6 +174,9 @@ func (ds *ServerImpl) RunSimpleFlow(
 	f.Start()
 	f.Wait()
 	f.Cleanup()
+	if mbox.err != nil {
+		ds.countError(errClassOutbox)
+	}
 	return mbox.err
 }
 
@@ -131,6 +186,7 @@ func (ds *ServerImpl) SetupFlow(ctx context.Context, req *SetupFlowRequest) (
 ) {
 	// Note: ctx will be canceled when the RPC completes, so we can't associate
 	// it with the transaction.
+	ds.PlanSizes.RecordValue(int64(req.Flow.Size()))
 
 	txn := ds.setupTxn(ds.ServerContext.Context, &req.Txn)
 	flowCtx := FlowCtx{
@@ -139,11 +195,14 @@ func (ds *ServerImpl) SetupFlow(ctx context.Context, req *SetupFlowRequest) (
 		evalCtx: &ds.evalCtx,
 		rpcCtx:  ds.RPCContext,
 		txn:     txn,
+
+		rowsReadCount: ds.RowsReadCount,
 	}
 	f := newFlow(flowCtx, ds.flowRegistry, nil)
 	err := f.setupFlow(&req.Flow)
 	if err != nil {
 		log.Errorf(ds.Context, err.Error(), "", err)
+		ds.countError(errClassSetup)
 		return nil, err
 	}
 	f.Start()
@@ -185,6 +244,7 @@ func (ds *ServerImpl) FlowStream(stream DistSQL_FlowStreamServer) error {
 	err := ds.flowStreamInt(stream)
 	if err != nil {
 		log.Errorf(ds.Context, err.Error(), "", err)
+		ds.countError(errClassStream)
 	}
 	return err
 }
@@ -25,9 +25,11 @@ import (
 	"github.com/cockroachdb/cockroach/base"
 	"github.com/cockroachdb/cockroach/internal/client"
 	"github.com/cockroachdb/cockroach/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/testutils"
 	"github.com/cockroachdb/cockroach/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/util/leaktest"
+	"github.com/cockroachdb/cockroach/util/metric"
 )
 
 func TestServer(t *testing.T) {
@@ -102,3 +104,68 @@ func TestServer(t *testing.T) {
 		t.Errorf("invalid results: %s, expected %s'", str, expected)
 	}
 }
+
+func TestServerErrorsCount(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, _, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop()
+
+	reg := metric.NewRegistry()
+	ds := NewServer(ServerContext{
+		Context:     context.Background(),
+		DB:          kvDB,
+		ErrorsCount: reg.LabeledCounter(MetricErrorsName, "class"),
+	})
+
+	txn := client.NewTxn(context.Background(), *kvDB)
+	req := &SetupFlowRequest{Txn: txn.Proto}
+	// Processors without outputs are not supported.
+	req.Flow = FlowSpec{
+		Processors: []ProcessorSpec{{
+			Core: ProcessorCoreUnion{TableReader: &TableReaderSpec{}},
+		}},
+	}
+	if _, err := ds.SetupFlow(context.Background(), req); !testutils.IsError(
+		err, "only single-output processors supported",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a := ds.ErrorsCount.WithLabelValues(errClassSetup).Count(); a != 1 {
+		t.Errorf("expected 1 setup error, got %d", a)
+	}
+}
+
+func TestServerPlanSizes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, _, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop()
+
+	reg := metric.NewRegistry()
+	ds := NewServer(ServerContext{
+		Context:   context.Background(),
+		DB:        kvDB,
+		PlanSizes: reg.Histograms(MetricPlanSizeName, MetricPlanSizeMaxVal, 1),
+	})
+
+	txn := client.NewTxn(context.Background(), *kvDB)
+	req := &SetupFlowRequest{Txn: txn.Proto}
+	req.Flow = FlowSpec{
+		Processors: []ProcessorSpec{{
+			Core: ProcessorCoreUnion{TableReader: &TableReaderSpec{}},
+		}},
+	}
+	// The plan size is recorded when the flow is received, even if its setup
+	// fails.
+	if _, err := ds.SetupFlow(context.Background(), req); err == nil {
+		t.Fatal("expected error")
+	}
+	h := reg.GetHistogram(MetricPlanSizeName + "-1h").Current()
+	if a := h.TotalCount(); a != 1 {
+		t.Errorf("expected 1 recorded plan, got %d", a)
+	}
+	if a, e := h.Max(), int64(req.Flow.Size()); a < e {
+		t.Errorf("expected plan size at least %d, got %d", e, a)
+	}
+}
@@ -123,6 +123,9 @@ func (rb *readerBase) nextRow() (sqlbase.EncDatumRow, error) {
 		if err != nil || fetcherRow == nil {
 			return nil, err
 		}
+		if rb.flowCtx.rowsReadCount != nil {
+			rb.flowCtx.rowsReadCount.Inc(1)
+		}
 
 		// TODO(radu): we are defeating the purpose of EncDatum here - we
 		// should modify RowFetcher to return EncDatums directly and avoid
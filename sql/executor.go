@@ -41,6 +41,7 @@ import (
 	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/stop"
 	"github.com/cockroachdb/cockroach/util/syncutil"
+	"github.com/cockroachdb/cockroach/util/timeutil"
 	"github.com/pkg/errors"
 )
 
@@ -66,6 +67,14 @@ const (
 	MetricDdlName         = "sql.ddl.count"
 	MetricMiscName        = "sql.misc.count"
 	MetricQueryName       = "sql.query.count"
+
+	MetricTxnRetryCountName   = "sql.txn.retry_count"
+	MetricTxnOpenDurationName = "sql.txn.open_duration_nanos"
+	MetricParseDurationName   = "sql.parse_duration_nanos"
+	MetricRowsReadName        = "sql.rows_read_total"
+
+	MetricIndexJoinRowsFetchedName = "sql.index_join.rows_fetched"
+	MetricTxnSavepointRollbackName = "kv.txn.savepoint_rollback_count"
 )
 
 // TODO(radu): experimental code for testing distSQL flows.
@@ -157,6 +166,23 @@ type Executor struct {
 	miscCount        *metric.Counter
 	queryCount       *metric.Counter
 
+	// txnSavepointRollbackCount counts the ROLLBACK TO SAVEPOINT statements
+	// received in any transaction state, which clients use to retry
+	// transactions.
+	txnSavepointRollbackCount *metric.Counter
+
+	// txnRetryCount records the number of retries (automatic or
+	// client-directed) of every SQL transaction when it finishes.
+	txnRetryCount metric.Histograms
+
+	// txnOpenDuration records the time every SQL transaction was open, from
+	// its start until it finishes or is aborted.
+	txnOpenDuration metric.Histograms
+
+	// parseDuration records the time spent parsing the SQL of each request
+	// and prepared statement.
+	parseDuration metric.Histograms
+
 	// System Config and mutex.
 	systemConfig   config.SystemConfig
 	databaseCache  *databaseCache
@@ -179,6 +205,19 @@ type ExecutorContext struct {
 	Clock        *hlc.Clock
 	DistSQLSrv   *distsql.ServerImpl
 
+	// SchemaChangeMetrics is shared with the SchemaChangeManager. It can be
+	// nil, in which case schema change metrics are not recorded.
+	SchemaChangeMetrics *SchemaChangeMetrics
+
+	// RowsReadCount counts the rows scanned from tables and indexes, before
+	// any filter is applied. It is shared with the DistSQL server and can be
+	// nil, in which case scanned rows are not counted.
+	RowsReadCount *metric.Counter
+
+	// IndexJoinRowsFetchedCount counts the primary index rows looked up by
+	// index joins. It can be nil, in which case they are not counted.
+	IndexJoinRowsFetchedCount *metric.Counter
+
 	TestingKnobs *ExecutorTestingKnobs
 }
 
@@ -255,6 +294,8 @@ func NewExecutor(ctx ExecutorContext, stopper *stop.Stopper, registry *metric.Re
 		txnCommitCount:   registry.Counter(MetricTxnCommitName),
 		txnAbortCount:    registry.Counter(MetricTxnAbortName),
 		txnRollbackCount: registry.Counter(MetricTxnRollbackName),
+		txnRetryCount:    registry.Histograms(MetricTxnRetryCountName, 100, 1),
+		parseDuration:    registry.Latency(MetricParseDurationName),
 		selectCount:      registry.Counter(MetricSelectName),
 		updateCount:      registry.Counter(MetricUpdateName),
 		insertCount:      registry.Counter(MetricInsertName),
@@ -262,6 +303,11 @@ func NewExecutor(ctx ExecutorContext, stopper *stop.Stopper, registry *metric.Re
 		ddlCount:         registry.Counter(MetricDdlName),
 		miscCount:        registry.Counter(MetricMiscName),
 		queryCount:       registry.Counter(MetricQueryName),
+
+		txnOpenDuration: registry.Histograms(
+			MetricTxnOpenDurationName, int64(metric.LongDurationWindow), 2),
+
+		txnSavepointRollbackCount: registry.Counter(MetricTxnSavepointRollbackName),
 	}
 	exec.systemConfigCond = sync.NewCond(exec.systemConfigMu.RLocker())
 
@@ -332,7 +378,9 @@ func (e *Executor) Prepare(
 	} else if traceSQL {
 		log.Tracef(session.Ctx(), "preparing: %s", query)
 	}
+	parseStart := timeutil.Now()
 	stmt, err := parser.ParseOne(query, parser.Syntax(session.Syntax))
+	e.parseDuration.RecordValue(timeutil.Since(parseStart).Nanoseconds())
 	if err != nil {
 		return nil, err
 	}
@@ -429,7 +477,9 @@ func (e *Executor) execRequest(session *Session, sql string) StatementResults {
 	var res StatementResults
 	txnState := &session.TxnState
 	planMaker := &session.planner
+	parseStart := timeutil.Now()
 	stmts, err := planMaker.parser.Parse(sql, parser.Syntax(session.Syntax))
+	e.parseDuration.RecordValue(timeutil.Since(parseStart).Nanoseconds())
 	if err != nil {
 		// A parse error occurred: we can't determine if there were multiple
 		// statements or only one, so just pretend there was one.
@@ -504,7 +554,13 @@ func (e *Executor) execRequest(session *Session, sql string) StatementResults {
 		var results []Result
 		origState := txnState.State
 
+		attempted := false
 		txnClosure := func(txn *client.Txn, opt *client.TxnExecOptions) error {
+			// The closure is called again for every automatic retry.
+			if attempted {
+				txnState.retries++
+			}
+			attempted = true
 			if txnState.State == Open && txnState.txn != txn {
 				panic(fmt.Sprintf("closure wasn't called in the txn we set up for it."+
 					"\ntxnState.txn:%+v\ntxn:%+v\ntxnState:%+v", txnState.txn, txn, txnState))
@@ -562,6 +618,11 @@ func (e *Executor) execRequest(session *Session, sql string) StatementResults {
 			// If execOpt.AutoCommit was set, then the txn no longer exists at this point.
 			txnState.resetStateAndTxn(NoTxn)
 		}
+		if origState != Aborted && (txnState.State == NoTxn || txnState.State == Aborted) {
+			// The SQL txn finished during this iteration.
+			e.txnRetryCount.RecordValue(txnState.retries)
+			e.txnOpenDuration.RecordValue(timeutil.Since(txnState.start).Nanoseconds())
+		}
 		// If the txn is in any state but Open, exec the schema changes. They'll
 		// short-circuit themselves if the mutation that queued them has been
 		// rolled back from the table descriptor.
@@ -770,6 +831,9 @@ func (e *Executor) execStmtInAbortedTxn(
 		var spName string
 		switch n := s.(type) {
 		case *parser.RollbackToSavepoint:
+			// Statements received in this state are not passed to
+			// updateStmtCounts.
+			e.txnSavepointRollbackCount.Inc(1)
 			spName = n.Savepoint
 		case *parser.Savepoint:
 			spName = n.Name
@@ -783,6 +847,7 @@ func (e *Executor) execStmtInAbortedTxn(
 			// Reset the state. Txn is Open again.
 			txnState.State = Open
 			txnState.retrying = true
+			txnState.retries++
 			// TODO(andrei/cdo): add a counter for user-directed retries.
 			return Result{}, nil
 		}
@@ -1158,6 +1223,8 @@ func (e *Executor) updateStmtCounts(stmt parser.Statement) {
 		e.txnCommitCount.Inc(1)
 	case *parser.RollbackTransaction:
 		e.txnRollbackCount.Inc(1)
+	case *parser.RollbackToSavepoint:
+		e.txnSavepointRollbackCount.Inc(1)
 	default:
 		if stmt.StatementType() == parser.DDL {
 			e.ddlCount.Inc(1)
@@ -227,6 +227,7 @@ func (n *indexJoinNode) Next() (bool, error) {
 			}
 		}
 
+		n.index.p.indexJoinRowsFetched(len(n.table.spans))
 		if log.V(3) {
 			log.Infof(n.index.p.ctx(), "table scan: %s", sqlbase.PrettySpans(n.table.spans, 0))
 		}
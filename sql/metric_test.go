@@ -18,14 +18,18 @@ package sql_test
 
 import (
 	"bytes"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/server"
 	"github.com/cockroachdb/cockroach/sql"
 	"github.com/cockroachdb/cockroach/storage/storagebase"
 	"github.com/cockroachdb/cockroach/testutils"
 	"github.com/cockroachdb/cockroach/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/util/leaktest"
+	"github.com/pkg/errors"
 )
 
 func TestQueryCounts(t *testing.T) {
@@ -162,3 +166,421 @@ func TestAbortCountErrorDuringTransaction(t *testing.T) {
 	checkCounterEQ(t, s, sql.MetricTxnBeginName, 1)
 	checkCounterEQ(t, s, sql.MetricSelectName, 1)
 }
+
+// TestSchemaChangeDurations tests that schema change durations are recorded
+// under the operation type of the schema change, and only when a schema change
+// actually ran.
+func TestSchemaChangeDurations(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := createTestServerParams()
+	// The first backfill attempt fails with a retryable error.
+	var backfillAttempts int32
+	params.Knobs.SQLExecutor = &sql.ExecutorTestingKnobs{
+		SchemaChangersStartBackfillNotification: func() error {
+			if atomic.AddInt32(&backfillAttempts, 1) == 1 {
+				return errors.New("context deadline exceeded")
+			}
+			return nil
+		},
+	}
+	// Disable the asynchronous schema changer so that only the schema changes
+	// executed synchronously by the statements below are recorded.
+	params.Knobs.SQLSchemaChangeManager = &sql.SchemaChangeManagerTestingKnobs{
+		AsyncSchemaChangerExecNotification: schemaChangeManagerDisabled,
+	}
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop()
+
+	registry := s.(*server.TestServer).Registry()
+	durationCount := func(opType string) int64 {
+		name := sql.MetricSchemaChangeDurationPrefix + "." + opType
+		h := registry.GetHistogram(name)
+		if h == nil {
+			t.Fatalf("histogram %s not found", name)
+		}
+		return h.Current().TotalCount()
+	}
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE d;
+CREATE TABLE d.t (k INT PRIMARY KEY);
+`); err != nil {
+		t.Fatal(err)
+	}
+	addColumn := durationCount("add-column")
+	addIndex := durationCount("add-index")
+
+	// GRANT notifies a schema changer for the table, but there is nothing for
+	// it to do.
+	if _, err := sqlDB.Exec(`GRANT SELECT ON TABLE d.t TO testuser`); err != nil {
+		t.Fatal(err)
+	}
+	if a := durationCount("add-column"); a != addColumn {
+		t.Errorf("expected %d add-column schema changes, got %d", addColumn, a)
+	}
+
+	// The schema change is retried once, but recorded only when it completes.
+	if _, err := sqlDB.Exec(`ALTER TABLE d.t ADD COLUMN v INT`); err != nil {
+		t.Fatal(err)
+	}
+	if a := atomic.LoadInt32(&backfillAttempts); a != 2 {
+		t.Errorf("expected 2 backfill attempts, got %d", a)
+	}
+	if a := durationCount("add-column"); a != addColumn+1 {
+		t.Errorf("expected %d add-column schema changes, got %d", addColumn+1, a)
+	}
+	if a := durationCount("add-index"); a != addIndex {
+		t.Errorf("expected %d add-index schema changes, got %d", addIndex, a)
+	}
+
+	if _, err := sqlDB.Exec(`CREATE INDEX foo ON d.t (v)`); err != nil {
+		t.Fatal(err)
+	}
+	if a := durationCount("add-index"); a != addIndex+1 {
+		t.Errorf("expected %d add-index schema changes, got %d", addIndex+1, a)
+	}
+}
+
+// TestSavepointRollbackCount tests that ROLLBACK TO SAVEPOINT statements are
+// counted, both when they retry a transaction and when they fail because there
+// is nothing to retry.
+func TestSavepointRollbackCount(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, cmdFilters := createTestServerParams()
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop()
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE db;
+CREATE TABLE db.t (k TEXT PRIMARY KEY, v TEXT);
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	// Inject a retryable error on the first INSERT of the marker value.
+	injectRetryOnMarker(cmdFilters)
+
+	// SAVEPOINT and RELEASE are not counted.
+	if _, err := sqlDB.Exec(
+		"BEGIN; SAVEPOINT cockroach_restart; RELEASE SAVEPOINT cockroach_restart; COMMIT",
+	); err != nil {
+		t.Fatal(err)
+	}
+	checkCounterEQ(t, s, sql.MetricTxnSavepointRollbackName, 0)
+
+	// A ROLLBACK TO SAVEPOINT without a preceding retryable error is counted,
+	// even though it fails.
+	txn, err := sqlDB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Exec("SAVEPOINT cockroach_restart"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Exec("ROLLBACK TO SAVEPOINT cockroach_restart"); !testutils.IsError(
+		err, "not in a retriable state",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := txn.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	checkCounterEQ(t, s, sql.MetricTxnSavepointRollbackName, 1)
+
+	// A client-directed retry.
+	txn, err = sqlDB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Exec("SAVEPOINT cockroach_restart"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Exec("INSERT INTO db.t VALUES ('key', 'marker')"); !testutils.IsError(
+		err, "restart transaction",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := txn.Exec("ROLLBACK TO SAVEPOINT cockroach_restart"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Exec("INSERT INTO db.t VALUES ('key', 'marker')"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Exec("RELEASE SAVEPOINT cockroach_restart"); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	checkCounterEQ(t, s, sql.MetricTxnSavepointRollbackName, 2)
+}
+
+// TestTxnRetryCount tests that the number of retries of every SQL transaction,
+// automatic or client-directed, is recorded when the transaction finishes.
+func TestTxnRetryCount(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, cmdFilters := createTestServerParams()
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop()
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE db;
+CREATE TABLE db.t (k TEXT PRIMARY KEY, v TEXT);
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	// Inject a retryable error on the first INSERT of every marker value.
+	injectRetryOnMarker(cmdFilters)
+
+	// The hour-long window is much longer than the test, so all the values
+	// recorded by the test are still in the histogram.
+	retryCount := getHistogram(t, s, sql.MetricTxnRetryCountName+"-1h")
+	checkRetries := func(expCount, expMax int64) {
+		h := retryCount.Current()
+		if a := h.TotalCount(); a != expCount {
+			t.Errorf("expected %d transactions, got %d", expCount, a)
+		}
+		if a := h.Max(); a != expMax {
+			t.Errorf("expected a maximum of %d retries, got %d", expMax, a)
+		}
+	}
+	count := retryCount.Current().TotalCount()
+
+	if _, err := sqlDB.Exec("INSERT INTO db.t VALUES ('a', 'v')"); err != nil {
+		t.Fatal(err)
+	}
+	count++
+	checkRetries(count, 0)
+
+	// The implicit transaction is retried automatically.
+	if _, err := sqlDB.Exec("INSERT INTO db.t VALUES ('b', 'marker1')"); err != nil {
+		t.Fatal(err)
+	}
+	count++
+	checkRetries(count, 1)
+
+	// A client-directed retry.
+	txn, err := sqlDB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Exec("SAVEPOINT cockroach_restart"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Exec("INSERT INTO db.t VALUES ('c', 'marker2')"); !testutils.IsError(
+		err, "restart transaction",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := txn.Exec("ROLLBACK TO SAVEPOINT cockroach_restart"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Exec("INSERT INTO db.t VALUES ('c', 'marker2')"); err != nil {
+		t.Fatal(err)
+	}
+	// The transaction has not finished yet.
+	checkRetries(count, 1)
+	if _, err := txn.Exec("RELEASE SAVEPOINT cockroach_restart"); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	count++
+	checkRetries(count, 1)
+}
+
+// TestTxnOpenDuration tests that the time every SQL transaction was open,
+// including across retries, is recorded when the transaction finishes or is
+// aborted.
+func TestTxnOpenDuration(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, cmdFilters := createTestServerParams()
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop()
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE db;
+CREATE TABLE db.t (k TEXT PRIMARY KEY, v TEXT);
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	// Inject a retryable error on the first INSERT of the marker value.
+	injectRetryOnMarker(cmdFilters)
+
+	const minOpen = 10 * time.Millisecond
+	openDuration := getHistogram(t, s, sql.MetricTxnOpenDurationName+"-1h")
+	checkOpenDuration := func(expCount int64) {
+		h := openDuration.Current()
+		if a := h.TotalCount(); a != expCount {
+			t.Errorf("expected %d transactions, got %d", expCount, a)
+		}
+		if a := time.Duration(h.Max()); a < minOpen {
+			t.Errorf("expected a maximum open duration of at least %s, got %s", minOpen, a)
+		}
+	}
+	count := openDuration.Current().TotalCount()
+
+	// A transaction that is retried once. Its open duration includes the
+	// time before the retry.
+	txn, err := sqlDB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Exec("SAVEPOINT cockroach_restart"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(minOpen)
+	if _, err := txn.Exec("INSERT INTO db.t VALUES ('a', 'marker')"); !testutils.IsError(
+		err, "restart transaction",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := txn.Exec("ROLLBACK TO SAVEPOINT cockroach_restart"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Exec("INSERT INTO db.t VALUES ('a', 'marker')"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Exec("RELEASE SAVEPOINT cockroach_restart"); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	count++
+	checkOpenDuration(count)
+
+	// A transaction aborted by an error is recorded when it is aborted.
+	txn, err = sqlDB.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Query("SELECT * FROM i_do.not_exist"); err == nil {
+		t.Fatal("Expected an error but didn't get one")
+	}
+	count++
+	checkOpenDuration(count)
+	if err := txn.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	checkOpenDuration(count)
+}
+
+// TestIndexJoinRowsFetchedCount tests that the primary index rows looked up by
+// index joins are counted.
+func TestIndexJoinRowsFetchedCount(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := createTestServerParams()
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop()
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE d;
+CREATE TABLE d.t (k INT PRIMARY KEY, v INT, w INT, INDEX foo (v));
+INSERT INTO d.t VALUES (1, 1, 1), (2, 2, 2), (3, 3, 3);
+`); err != nil {
+		t.Fatal(err)
+	}
+	checkCounterEQ(t, s, sql.MetricIndexJoinRowsFetchedName, 0)
+
+	// The index covers the query, so there is no index join.
+	if _, err := sqlDB.Exec(`SELECT v FROM d.t@foo WHERE v >= 2`); err != nil {
+		t.Fatal(err)
+	}
+	checkCounterEQ(t, s, sql.MetricIndexJoinRowsFetchedName, 0)
+
+	// w is only in the primary index, which is looked up for every matching
+	// row of the secondary index.
+	if _, err := sqlDB.Exec(`SELECT w FROM d.t@foo WHERE v >= 2`); err != nil {
+		t.Fatal(err)
+	}
+	checkCounterEQ(t, s, sql.MetricIndexJoinRowsFetchedName, 2)
+}
+
+// TestParseDuration tests that the time spent parsing every request is
+// recorded.
+func TestParseDuration(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := createTestServerParams()
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop()
+
+	parseDuration := getHistogram(t, s, sql.MetricParseDurationName+"-1h")
+	count := parseDuration.Current().TotalCount()
+
+	// The statements of a request are parsed together.
+	if _, err := sqlDB.Exec("SELECT 1; SELECT 2"); err != nil {
+		t.Fatal(err)
+	}
+	if a, e := parseDuration.Current().TotalCount(), count+1; a != e {
+		t.Errorf("expected %d parse durations, got %d", e, a)
+	}
+}
+
+// TestRowsReadCount tests that the rows scanned from tables are counted before
+// any filter is applied.
+func TestRowsReadCount(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := createTestServerParams()
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop()
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE d;
+CREATE TABLE d.t (k INT PRIMARY KEY, v INT);
+INSERT INTO d.t VALUES (1, 1), (2, 2), (3, 3);
+`); err != nil {
+		t.Fatal(err)
+	}
+	rowsRead := s.MustGetSQLCounter(sql.MetricRowsReadName)
+
+	// v is not indexed, so the whole table is scanned.
+	if _, err := sqlDB.Exec(`SELECT k FROM d.t WHERE v > 2`); err != nil {
+		t.Fatal(err)
+	}
+	checkCounterEQ(t, s, sql.MetricRowsReadName, rowsRead+3)
+}
+
+// TestSchemaChangeBackfillRowsCount tests that the rows processed by column
+// and index backfills are counted.
+func TestSchemaChangeBackfillRowsCount(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := createTestServerParams()
+	// Disable the asynchronous schema changer so that only the backfills
+	// executed synchronously by the statements below are counted.
+	params.Knobs.SQLSchemaChangeManager = &sql.SchemaChangeManagerTestingKnobs{
+		AsyncSchemaChangerExecNotification: schemaChangeManagerDisabled,
+	}
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop()
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE d;
+CREATE TABLE d.t (k INT PRIMARY KEY);
+INSERT INTO d.t VALUES (1), (2), (3);
+`); err != nil {
+		t.Fatal(err)
+	}
+	columnName := sql.MetricSchemaChangeBackfillRowsPrefix + ".column"
+	indexName := sql.MetricSchemaChangeBackfillRowsPrefix + ".index"
+	columnRows := s.MustGetSQLCounter(columnName)
+	indexRows := s.MustGetSQLCounter(indexName)
+
+	// A column with a default value needs a backfill.
+	if _, err := sqlDB.Exec(`ALTER TABLE d.t ADD COLUMN v INT DEFAULT 1`); err != nil {
+		t.Fatal(err)
+	}
+	checkCounterEQ(t, s, columnName, columnRows+3)
+	checkCounterEQ(t, s, indexName, indexRows)
+
+	if _, err := sqlDB.Exec(`CREATE INDEX foo ON d.t (v)`); err != nil {
+		t.Fatal(err)
+	}
+	checkCounterEQ(t, s, indexName, indexRows+3)
+}
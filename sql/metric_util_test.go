@@ -19,9 +19,15 @@
 package sql_test
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/roachpb"
+	"github.com/cockroachdb/cockroach/server"
+	"github.com/cockroachdb/cockroach/storage/storagebase"
 	"github.com/cockroachdb/cockroach/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/util/metric"
+	"github.com/cockroachdb/cockroach/util/syncutil"
 	"github.com/pkg/errors"
 )
 
@@ -40,3 +46,37 @@ func checkCounterGE(
 		t.Error(errors.Errorf("stat %s: expected: actual %d >= %d", key, a, e))
 	}
 }
+
+func getHistogram(
+	t *testing.T, s serverutils.TestServerInterface, key string,
+) *metric.Histogram {
+	h := s.(*server.TestServer).Registry().GetHistogram(key)
+	if h == nil {
+		t.Fatalf("histogram %s not found", key)
+	}
+	return h
+}
+
+// injectRetryOnMarker makes the first INSERT of every value containing
+// "marker" fail with a retryable error.
+func injectRetryOnMarker(cmdFilters *CommandFilters) {
+	var mu syncutil.Mutex
+	restarted := make(map[string]bool)
+	cmdFilters.AppendFilter(func(args storagebase.FilterArgs) *roachpb.Error {
+		switch req := args.Req.(type) {
+		// SQL INSERT generates ConditionalPuts for unique indexes (such as the PK).
+		case *roachpb.ConditionalPutRequest:
+			if !bytes.Contains(req.Value.RawBytes, []byte("marker")) {
+				return nil
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if v := string(req.Value.RawBytes); !restarted[v] {
+				restarted[v] = true
+				return roachpb.NewErrorWithTxn(
+					roachpb.NewTransactionRetryError(), args.Hdr.Txn)
+			}
+		}
+		return nil
+	}, false)
+}
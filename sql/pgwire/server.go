@@ -47,6 +47,23 @@ const (
 	MetricConnsName    = "sql.conns"
 	MetricBytesInName  = "sql.bytesin"
 	MetricBytesOutName = "sql.bytesout"
+
+	MetricConnIdleDurationName = "sql.conn.idle_duration_nanos"
+	MetricAuthFailuresName     = "sql.conn.auth_failures_total"
+	MetricMessageSizeName      = "sql.pgwire.message_size_bytes"
+)
+
+// The reasons with which failed authentication attempts are labeled.
+const (
+	// authFailureSSLRequired is used for insecure connections to a secure
+	// server.
+	authFailureSSLRequired = "ssl_required"
+	// authFailureCertificate is used when no user could be determined from the
+	// client certificate.
+	authFailureCertificate = "certificate"
+	// authFailureUser is used when the requested user is missing or does not
+	// match the client certificate.
+	authFailureUser = "user"
 )
 
 const (
@@ -79,6 +96,17 @@ type serverMetrics struct {
 	bytesInCount  *metric.Counter
 	bytesOutCount *metric.Counter
 	conns         *metric.Counter
+
+	// connIdleDuration records the time between a connection reporting that
+	// it is ready for a query and the client sending its next message.
+	connIdleDuration metric.Histograms
+
+	// authFailures counts failed authentication attempts, labeled by reason.
+	authFailures *metric.LabeledCounter
+
+	// messageSize records the size of every message fully read from a
+	// client, including its length prefix.
+	messageSize metric.Histograms
 }
 
 func newServerMetrics(reg *metric.Registry) *serverMetrics {
@@ -86,6 +114,11 @@ func newServerMetrics(reg *metric.Registry) *serverMetrics {
 		conns:         reg.Counter(MetricConnsName),
 		bytesInCount:  reg.Counter(MetricBytesInName),
 		bytesOutCount: reg.Counter(MetricBytesOutName),
+
+		connIdleDuration: reg.Histograms(
+			MetricConnIdleDurationName, int64(metric.LongDurationWindow), 2),
+		authFailures: reg.LabeledCounter(MetricAuthFailuresName, "reason"),
+		messageSize:  reg.Histograms(MetricMessageSizeName, maxMessageSize+4, 1),
 	}
 }
 
@@ -219,6 +252,7 @@ func (s *Server) ServeConn(conn net.Conn) error {
 			return v3conn.sendInternalError(argsErr.Error())
 		}
 		if errSSLRequired {
+			s.metrics.authFailures.WithLabelValues(authFailureSSLRequired).Inc(1)
 			return v3conn.sendInternalError(ErrSSLRequired)
 		}
 		if draining {
@@ -231,6 +265,7 @@ func (s *Server) ServeConn(conn net.Conn) error {
 			tlsState := tlsConn.ConnectionState()
 			authenticationHook, err := security.UserAuthHook(s.context.Insecure, &tlsState)
 			if err != nil {
+				s.metrics.authFailures.WithLabelValues(authFailureCertificate).Inc(1)
 				return v3conn.sendInternalError(err.Error())
 			}
 			return v3conn.serve(authenticationHook)
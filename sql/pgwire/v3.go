@@ -23,6 +23,7 @@ import (
 	"net"
 	"reflect"
 	"strconv"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -31,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/timeutil"
 	"github.com/cockroachdb/cockroach/util/tracing"
 	"github.com/cockroachdb/pq/oid"
 	"github.com/pkg/errors"
@@ -194,6 +196,7 @@ func (c *v3Conn) serve(authenticationHook func(string, bool) error) error {
 
 	if authenticationHook != nil {
 		if err := authenticationHook(c.session.User, true /* public */); err != nil {
+			c.metrics.authFailures.WithLabelValues(authFailureUser).Inc(1)
 			return c.sendInternalError(err.Error())
 		}
 	}
@@ -214,6 +217,9 @@ func (c *v3Conn) serve(authenticationHook func(string, bool) error) error {
 		return err
 	}
 
+	// idleStart is set when the client has been told that we are ready for
+	// the next query, and is cleared when its next message arrives.
+	var idleStart time.Time
 	for {
 		if !c.doingExtendedQueryMessage {
 			c.writeBuf.initMsg(serverMsgReady)
@@ -248,12 +254,18 @@ func (c *v3Conn) serve(authenticationHook func(string, bool) error) error {
 			if err := c.wr.Flush(); err != nil {
 				return err
 			}
+			idleStart = timeutil.Now()
 		}
 		typ, n, err := c.readBuf.readTypedMsg(c.rd)
 		c.metrics.bytesInCount.Inc(int64(n))
 		if err != nil {
 			return err
 		}
+		c.metrics.messageSize.RecordValue(int64(n))
+		if !idleStart.IsZero() {
+			c.metrics.connIdleDuration.RecordValue(timeutil.Since(idleStart).Nanoseconds())
+			idleStart = time.Time{}
+		}
 		// When an error occurs handling an extended query message, we have to ignore
 		// any messages until we get a sync.
 		if c.ignoreTillSync && typ != clientMsgSync {
@@ -43,6 +43,7 @@ import (
 	"github.com/cockroachdb/cockroach/util"
 	"github.com/cockroachdb/cockroach/util/leaktest"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/pq"
 	"github.com/pkg/errors"
 )
@@ -1086,11 +1087,19 @@ func TestSQLNetworkMetrics(t *testing.T) {
 	if _, _, err := checkSQLNetworkMetrics(s, 0, 0, 0, 0); err != nil {
 		t.Fatal(err)
 	}
+	messageSize := getHistogram(t, s, pgwire.MetricMessageSizeName+"-1h")
+	if a := messageSize.Current().TotalCount(); a != 0 {
+		t.Fatalf("expected no message sizes, got %d", a)
+	}
 
 	// A single query should give us some I/O.
 	if err := trivialQuery(pgURL); err != nil {
 		t.Fatal(err)
 	}
+	// The query message at least has been read from the client.
+	if a := messageSize.Current().TotalCount(); a == 0 {
+		t.Error("expected message sizes to be recorded")
+	}
 	bytesIn, bytesOut, err := checkSQLNetworkMetrics(s, minbytes, minbytes, 300, 300)
 	if err != nil {
 		t.Fatal(err)
@@ -1137,6 +1146,82 @@ func TestSQLNetworkMetrics(t *testing.T) {
 	}
 }
 
+func TestSQLConnIdleDuration(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop()
+
+	pgURL, cleanupFn := sqlutils.PGUrl(t, s.ServingAddr(), security.RootUser,
+		"TestSQLConnIdleDuration")
+	defer cleanupFn()
+
+	db, err := gosql.Open("postgres", pgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// Use a single connection, so that it is idle between the queries below.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec("SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+	idleDuration := getHistogram(t, s, pgwire.MetricConnIdleDurationName+"-1h")
+	count := idleDuration.Current().TotalCount()
+
+	const minIdle = 10 * time.Millisecond
+	time.Sleep(minIdle)
+	if _, err := db.Exec("SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+	h := idleDuration.Current()
+	if a := h.TotalCount(); a <= count {
+		t.Errorf("expected more than %d idle durations, got %d", count, a)
+	}
+	if a := time.Duration(h.Max()); a < minIdle {
+		t.Errorf("expected a maximum idle duration of at least %s, got %s", minIdle, a)
+	}
+}
+
+func TestSQLAuthFailures(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop()
+
+	// Request a different user than the one in the client certificate.
+	pgURL, cleanupFn := sqlutils.PGUrl(t, s.ServingAddr(), security.RootUser,
+		"TestSQLAuthFailures")
+	defer cleanupFn()
+	pgURL.User = url.User(server.TestUser)
+
+	authFailures := func(reason string) int64 {
+		var count int64
+		s.(*server.TestServer).Registry().Each(func(name string, v interface{}) {
+			if name != pgwire.MetricAuthFailuresName {
+				return
+			}
+			v.(*metric.LabeledCounter).EachLabeled(func(values []string, c *metric.Counter) {
+				if len(values) == 1 && values[0] == reason {
+					count = c.Count()
+				}
+			})
+		})
+		return count
+	}
+	if a := authFailures("user"); a != 0 {
+		t.Fatalf("expected no authentication failures, got %d", a)
+	}
+
+	if err := trivialQuery(pgURL); !testutils.IsError(err, "requested user is testuser") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a := authFailures("user"); a != 1 {
+		t.Errorf("expected 1 authentication failure, got %d", a)
+	}
+}
+
 func TestPrepareSyntax(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
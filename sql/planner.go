@@ -203,6 +203,21 @@ func (p *planner) resetForBatch(e *Executor) {
 	p.evalCtx.ReCache = e.reCache
 }
 
+// rowRead records that a row was scanned from a table or index.
+func (p *planner) rowRead() {
+	if p.execCtx != nil && p.execCtx.RowsReadCount != nil {
+		p.execCtx.RowsReadCount.Inc(1)
+	}
+}
+
+// indexJoinRowsFetched records that an index join looked up the given number
+// of rows in the primary index.
+func (p *planner) indexJoinRowsFetched(n int) {
+	if p.execCtx != nil && p.execCtx.IndexJoinRowsFetchedCount != nil {
+		p.execCtx.IndexJoinRowsFetchedCount.Inc(int64(n))
+	}
+}
+
 // query initializes a planNode from a SQL statement string.  This
 // should not be used directly; queryRow() and exec() below should be
 // used instead.
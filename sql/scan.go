@@ -199,6 +199,7 @@ func (n *scanNode) Next() (bool, error) {
 		if err != nil || n.row == nil {
 			return false, err
 		}
+		n.p.rowRead()
 		passesFilter, err := sqlbase.RunFilter(n.filter, &n.p.evalCtx)
 		if err != nil {
 			return false, err
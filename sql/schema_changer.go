@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/sql/parser"
 	"github.com/cockroachdb/cockroach/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/retry"
 	"github.com/cockroachdb/cockroach/util/stop"
 	"github.com/cockroachdb/cockroach/util/timeutil"
@@ -46,6 +47,123 @@ var (
 	MinSchemaChangeLeaseDuration = time.Minute
 )
 
+// MetricSchemaChangeDurationPrefix is the prefix of the names of the schema
+// change duration histograms. The full names are of the form
+// <prefix>.<operation type>.
+const MetricSchemaChangeDurationPrefix = "sql.schema_change.duration"
+
+// MetricSchemaChangeBackfillRowsPrefix is the prefix of the names of the
+// counters of rows processed by schema change backfills. The full names are of
+// the form <prefix>.<backfill type>.
+const MetricSchemaChangeBackfillRowsPrefix = "sql.schema_change.backfill_rows_processed"
+
+// The backfill types for which processed rows are counted.
+const (
+	backfillTypeColumn = "column"
+	backfillTypeIndex  = "index"
+)
+
+// The operation types for which schema change durations are recorded.
+const (
+	schemaChangeDropTable  = "drop-table"
+	schemaChangeAddTable   = "add-table"
+	schemaChangeRename     = "rename"
+	schemaChangeAddColumn  = "add-column"
+	schemaChangeDropColumn = "drop-column"
+	schemaChangeAddIndex   = "add-index"
+	schemaChangeDropIndex  = "drop-index"
+)
+
+var schemaChangeTypes = []string{
+	schemaChangeDropTable,
+	schemaChangeAddTable,
+	schemaChangeRename,
+	schemaChangeAddColumn,
+	schemaChangeDropColumn,
+	schemaChangeAddIndex,
+	schemaChangeDropIndex,
+}
+
+// SchemaChangeMetrics holds the metrics recorded by schema changers, which
+// run both synchronously on the gateway and in the SchemaChangeManager.
+type SchemaChangeMetrics struct {
+	// Durations holds, for every operation type, the end-to-end duration of
+	// schema changes, whether they succeed or fail. A schema change which is
+	// retried is recorded once, when it completes.
+	Durations map[string]*metric.Histogram
+	// BackfillRowsProcessed holds, for every backfill type, the number of
+	// primary index rows processed by committed backfill chunks.
+	BackfillRowsProcessed map[string]*metric.Counter
+}
+
+// NewSchemaChangeMetrics returns a new instance of SchemaChangeMetrics that
+// contains metrics which have been registered with the provided Registry.
+func NewSchemaChangeMetrics(registry *metric.Registry) *SchemaChangeMetrics {
+	m := &SchemaChangeMetrics{
+		Durations:             make(map[string]*metric.Histogram),
+		BackfillRowsProcessed: make(map[string]*metric.Counter),
+	}
+	for _, opType := range schemaChangeTypes {
+		// Schema changes are rare, so a single window with one significant
+		// digit keeps the memory footprint small.
+		m.Durations[opType] = registry.Histogram(MetricSchemaChangeDurationPrefix+"."+opType,
+			metric.LongDurationWindow, int64(metric.LongDurationWindow), 1)
+	}
+	for _, backfillType := range []string{backfillTypeColumn, backfillTypeIndex} {
+		m.BackfillRowsProcessed[backfillType] = registry.Counter(
+			MetricSchemaChangeBackfillRowsPrefix + "." + backfillType)
+	}
+	return m
+}
+
+// recordDuration records the duration of a schema change of the given
+// operation type. It is a no-op on a nil receiver.
+func (m *SchemaChangeMetrics) recordDuration(opType string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.Durations[opType].RecordValue(d.Nanoseconds())
+}
+
+// recordBackfillRows records that a backfill of the given type processed the
+// given number of rows. It is a no-op on a nil receiver.
+func (m *SchemaChangeMetrics) recordBackfillRows(backfillType string, rows int) {
+	if m == nil {
+		return
+	}
+	m.BackfillRowsProcessed[backfillType].Inc(int64(rows))
+}
+
+// schemaChangeType returns the operation type of the schema change for the
+// given mutation on the table, or the empty string if there is nothing to do.
+func schemaChangeType(table *sqlbase.TableDescriptor, mutationID sqlbase.MutationID) string {
+	switch {
+	case table.Deleted():
+		return schemaChangeDropTable
+	case table.Adding():
+		return schemaChangeAddTable
+	case table.Renamed():
+		return schemaChangeRename
+	}
+	for _, m := range table.Mutations {
+		if m.MutationID != mutationID {
+			continue
+		}
+		add := m.Direction == sqlbase.DescriptorMutation_ADD
+		switch {
+		case m.GetColumn() != nil && add:
+			return schemaChangeAddColumn
+		case m.GetColumn() != nil:
+			return schemaChangeDropColumn
+		case m.GetIndex() != nil && add:
+			return schemaChangeAddIndex
+		case m.GetIndex() != nil:
+			return schemaChangeDropIndex
+		}
+	}
+	return ""
+}
+
 // SchemaChanger is used to change the schema on a table.
 type SchemaChanger struct {
 	tableID    sqlbase.ID
@@ -54,9 +172,14 @@ type SchemaChanger struct {
 	db         client.DB
 	leaseMgr   *LeaseManager
 	evalCtx    parser.EvalContext
+	metrics    *SchemaChangeMetrics
 	// The SchemaChangeManager can attempt to execute this schema
 	// changer after this time.
 	execAfter time.Time
+	// execStart, if set, is the time the first attempt to execute this
+	// schema changer started. It is used when recording the duration of the
+	// schema change.
+	execStart time.Time
 }
 
 func (sc *SchemaChanger) truncateAndDropTable(
@@ -198,7 +321,12 @@ func isSchemaChangeRetryError(err error) bool {
 func (sc SchemaChanger) exec(
 	startBackfillNotification func() error,
 	oldNameNotInUseNotification func(),
-) error {
+) (err error) {
+	start := sc.execStart
+	if start.IsZero() {
+		start = timeutil.Now()
+	}
+
 	// Acquire lease.
 	lease, err := sc.AcquireLease()
 	if err != nil {
@@ -224,6 +352,16 @@ func (sc SchemaChanger) exec(
 	}
 	table := desc.GetTable()
 
+	if opType := schemaChangeType(table, sc.mutationID); opType != "" {
+		defer func() {
+			// An attempt which fails with a retryable error leaves the
+			// schema change in progress.
+			if err == nil || !isSchemaChangeRetryError(err) {
+				sc.metrics.recordDuration(opType, timeutil.Since(start))
+			}
+		}()
+	}
+
 	if table.Deleted() {
 		lease, err = sc.ExtendLease(lease)
 		if err != nil {
@@ -650,6 +788,7 @@ type SchemaChangeManager struct {
 	db           client.DB
 	gossip       *gossip.Gossip
 	leaseMgr     *LeaseManager
+	metrics      *SchemaChangeMetrics
 	testingKnobs *SchemaChangeManagerTestingKnobs
 	// Create a schema changer for every outstanding schema change seen.
 	schemaChangers map[sqlbase.ID]SchemaChanger
@@ -661,11 +800,13 @@ func NewSchemaChangeManager(
 	db client.DB,
 	gossip *gossip.Gossip,
 	leaseMgr *LeaseManager,
+	metrics *SchemaChangeMetrics,
 ) *SchemaChangeManager {
 	return &SchemaChangeManager{
 		db:             db,
 		gossip:         gossip,
 		leaseMgr:       leaseMgr,
+		metrics:        metrics,
 		testingKnobs:   testingKnobs,
 		schemaChangers: make(map[sqlbase.ID]SchemaChanger),
 	}
@@ -712,6 +853,7 @@ func (s *SchemaChangeManager) Start(stopper *stop.Stopper) {
 					nodeID:   roachpb.NodeID(s.leaseMgr.nodeID),
 					db:       s.db,
 					leaseMgr: s.leaseMgr,
+					metrics:  s.metrics,
 				}
 				// Keep track of existing schema changers.
 				oldSchemaChangers := make(map[sqlbase.ID]struct{}, len(s.schemaChangers))
@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/util/envutil"
 	"github.com/cockroachdb/cockroach/util/log"
 	"github.com/cockroachdb/cockroach/util/retry"
+	"github.com/cockroachdb/cockroach/util/timeutil"
 	"github.com/cockroachdb/cockroach/util/tracing"
 	basictracer "github.com/opentracing/basictracer-go"
 	opentracing "github.com/opentracing/opentracing-go"
@@ -161,6 +162,10 @@ type txnState struct {
 	// except it's reset in between client round trips.
 	autoRetry bool
 
+	// The number of times the txn has been retried, either automatically or
+	// at the client's request.
+	retries int64
+
 	// A COMMIT statement has been processed. Useful for allowing the txn to
 	// survive retriable errors if it will be auto-retried (BEGIN; ... COMMIT; in
 	// the same batch), but not if the error needs to be reported to the user.
@@ -176,11 +181,15 @@ type txnState struct {
 	// The timestamp to report for current_timestamp(), now() etc.
 	// This must be constant for the lifetime of a SQL transaction.
 	sqlTimestamp time.Time
+
+	// The time at which the txn was started, used for the open duration metric.
+	start time.Time
 }
 
 // reset creates a new Txn and initializes it using the session defaults.
 func (ts *txnState) reset(ctx context.Context, e *Executor, s *Session) {
 	*ts = txnState{}
+	ts.start = timeutil.Now()
 	ts.txn = client.NewTxn(ctx, *e.ctx.DB)
 	ts.txn.Context = s.context
 	ts.txn.Proto.Isolation = s.DefaultIsolationLevel
@@ -309,6 +318,8 @@ func (scc *schemaChangerCollection) execSchemaChanges(
 	for _, scEntry := range scc.schemaChangers {
 		sc := &scEntry.sc
 		sc.db = *e.ctx.DB
+		sc.metrics = e.ctx.SchemaChangeMetrics
+		sc.execStart = timeutil.Now()
 		for r := retry.Start(base.DefaultRetryOptions()); r.Next(); {
 			if done, err := sc.IsDone(); err != nil {
 				log.Warning(e.ctx.Context, err)
@@ -17,6 +17,8 @@
 package storage_test
 
 import (
+	"bytes"
+	"fmt"
 	"sync"
 	"testing"
 
@@ -25,6 +27,9 @@ import (
 	"github.com/cockroachdb/cockroach/internal/client"
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/storage"
+	"github.com/cockroachdb/cockroach/storage/engine"
+	"github.com/cockroachdb/cockroach/util"
+	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/leaktest"
 	"github.com/pkg/errors"
 )
@@ -37,6 +42,14 @@ func getGauge(t *testing.T, s *storage.Store, key string) int64 {
 	return gauge.Value()
 }
 
+func getGaugeFloat64(t *testing.T, s *storage.Store, key string) float64 {
+	gauge := s.Registry().GetGaugeFloat64(key)
+	if gauge == nil {
+		t.Fatal(errors.Errorf("store did not contain gauge %s", key))
+	}
+	return gauge.Value()
+}
+
 func getCounter(t *testing.T, s *storage.Store, key string) int64 {
 	counter := s.Registry().GetCounter(key)
 	if counter == nil {
@@ -57,6 +70,18 @@ func checkCounter(t *testing.T, s *storage.Store, key string, e int64) {
 	}
 }
 
+// checkHistogramCount checks the number of values recorded in the current
+// window of the given histogram.
+func checkHistogramCount(t *testing.T, s *storage.Store, key string, e int64) {
+	h := s.Registry().GetHistogram(key)
+	if h == nil {
+		t.Fatal(errors.Errorf("store did not contain histogram %s", key))
+	}
+	if a := h.Current().TotalCount(); a != e {
+		t.Error(errors.Errorf("%s for store: actual %d != expected %d", key, a, e))
+	}
+}
+
 func verifyStats(t *testing.T, mtc *multiTestContext, storeIdxSlice ...int) {
 	var stores []*storage.Store
 	var wg sync.WaitGroup
@@ -157,12 +182,29 @@ func verifyRocksDBStats(t *testing.T, s *storage.Store) {
 		{"rocksdb.flushes", 1},
 		{"rocksdb.compactions", 0},
 		{"rocksdb.table-readers-mem-estimate", 50},
+		{"storage.wal.bytes_written", 5000},
+		{"storage.iterator.seek_count", 1},
+		{"storage.iterator.next_count", 1},
 	}
 	for _, tc := range testcases {
 		if a := getGauge(t, s, tc.gaugeName); a < tc.min {
 			t.Errorf("gauge %s = %d < min %d", tc.gaugeName, a, tc.min)
 		}
 	}
+
+	hits := getGauge(t, s, "rocksdb.block.cache.hits")
+	misses := getGauge(t, s, "rocksdb.block.cache.misses")
+	if a, e := getGaugeFloat64(t, s, "storage.engine.block_cache_hit_rate"),
+		float64(hits)/float64(hits+misses); a != e {
+		t.Errorf("block cache hit rate = %f != expected %f", a, e)
+	}
+
+	checked := getGauge(t, s, "rocksdb.bloom.filter.prefix.checked")
+	useful := getGauge(t, s, "rocksdb.bloom.filter.prefix.useful")
+	if a, e := getGaugeFloat64(t, s, "storage.bloom_filter.hit_rate"),
+		float64(useful)/float64(checked); a != e {
+		t.Errorf("bloom filter hit rate = %f != expected %f", a, e)
+	}
 }
 
 func TestStoreMetrics(t *testing.T) {
@@ -245,3 +287,251 @@ func TestStoreMetrics(t *testing.T) {
 	verifyRocksDBStats(t, mtc.stores[0])
 	verifyRocksDBStats(t, mtc.stores[1])
 }
+
+// TestStoreGCMetrics verifies that the bytes and key-value pairs removed by
+// GC requests are added to the store's GC metrics.
+func TestStoreGCMetrics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	store, stopper, _ := createTestStore(t)
+	defer stopper.Stop()
+
+	// Write two versions of a key, the older of which can be collected.
+	key := roachpb.Key("a")
+	var timestamps []hlc.Timestamp
+	for _, value := range []string{"old", "new"} {
+		ts := store.Clock().Now()
+		pArgs := putArgs(key, []byte(value))
+		if _, pErr := client.SendWrappedWith(rg1(store), nil, roachpb.Header{
+			Timestamp: ts,
+		}, &pArgs); pErr != nil {
+			t.Fatal(pErr)
+		}
+		timestamps = append(timestamps, ts)
+	}
+	checkCounter(t, store, "storage.gc.bytes_freed", 0)
+	checkCounter(t, store, "storage.gc.keys_freed", 0)
+
+	gcArgs := roachpb.GCRequest{
+		Span: roachpb.Span{
+			Key:    key,
+			EndKey: key.Next(),
+		},
+		Keys: []roachpb.GCRequest_GCKey{{Key: key, Timestamp: timestamps[0]}},
+	}
+	if _, pErr := client.SendWrappedWith(rg1(store), nil, roachpb.Header{
+		Timestamp: store.Clock().Now(),
+	}, &gcArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	checkCounter(t, store, "storage.gc.keys_freed", 1)
+	if a := getCounter(t, store, "storage.gc.bytes_freed"); a <= 0 {
+		t.Errorf("expected freed bytes to be counted, got %d", a)
+	}
+}
+
+// TestStoreRaftLogBehindMetric verifies that the Raft log entries which have
+// not been acknowledged by a follower are counted by the leader's store.
+func TestStoreRaftLogBehindMetric(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	mtc := startMultiTestContext(t, 3)
+	defer mtc.Stop()
+
+	// Disable the raft log truncation, which would send a snapshot to the
+	// stopped follower below instead of the missing entries.
+	for _, s := range mtc.stores {
+		s.SetRaftLogQueueActive(false)
+	}
+
+	const rangeID = roachpb.RangeID(1)
+	mtc.replicateRange(rangeID, 1, 2)
+	key := roachpb.Key("a")
+	if _, err := mtc.dbs[0].Inc(key, 5); err != nil {
+		t.Fatal(err)
+	}
+	mtc.waitForValues(key, []int64{5, 5, 5})
+
+	// raftLogBehind returns the gauge summed over the running stores.
+	raftLogBehind := func() int64 {
+		var behind int64
+		for _, s := range mtc.stores {
+			if s == nil {
+				continue
+			}
+			if err := s.ComputeMetrics(); err != nil {
+				t.Fatal(err)
+			}
+			behind += getGauge(t, s, "raft.log.behind_count")
+		}
+		return behind
+	}
+	util.SucceedsSoon(t, func() error {
+		if a := raftLogBehind(); a != 0 {
+			return errors.Errorf("expected no entries behind, got %d", a)
+		}
+		return nil
+	})
+
+	// Entries committed while a follower is down are behind until it comes
+	// back.
+	mtc.stopStore(2)
+	const writes = 3
+	for i := 0; i < writes; i++ {
+		if _, err := mtc.dbs[0].Inc(key, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	util.SucceedsSoon(t, func() error {
+		if a := raftLogBehind(); a < writes {
+			return errors.Errorf("expected at least %d entries behind, got %d", writes, a)
+		}
+		return nil
+	})
+
+	mtc.restartStore(2)
+	mtc.waitForValues(key, []int64{5 + writes, 5 + writes, 5 + writes})
+	util.SucceedsSoon(t, func() error {
+		if a := raftLogBehind(); a != 0 {
+			return errors.Errorf("expected no entries behind, got %d", a)
+		}
+		return nil
+	})
+}
+
+// TestStoreLeaseExpiringSoonMetric verifies that the range leases held by the
+// store are counted once they are about to stop covering new requests.
+func TestStoreLeaseExpiringSoonMetric(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	store, stopper, manual := createTestStore(t)
+	defer stopper.Stop()
+
+	// Make sure the store holds the lease on the first range.
+	pArgs := putArgs(roachpb.Key("a"), []byte("value"))
+	if _, pErr := client.SendWrapped(rg1(store), nil, &pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	lease, _ := store.LookupReplica(roachpb.RKeyMin, nil).GetLease()
+	if !lease.OwnedBy(store.StoreID()) {
+		t.Fatalf("expected the store to hold the lease, got %+v", lease)
+	}
+
+	expiringSoon := func() int64 {
+		if err := store.ComputeMetrics(); err != nil {
+			t.Fatal(err)
+		}
+		return getGauge(t, store, "kv.range.lease_expiry_soon_count")
+	}
+	// The replication gauges are only computed once the system config has been
+	// gossiped; the gauge stays at zero until then, so wait for it first.
+	util.SucceedsSoon(t, func() error {
+		if _, ok := store.Gossip().GetSystemConfig(); !ok {
+			return errors.New("system config not yet available")
+		}
+		return nil
+	})
+	// The lease has just been acquired.
+	if a := expiringSoon(); a != 0 {
+		t.Errorf("expected no leases expiring soon, got %d", a)
+	}
+
+	// Move the clock to just before the lease's stasis period.
+	manual.Set(lease.StartStasis.WallTime - 1)
+	if a := expiringSoon(); a < 1 {
+		t.Errorf("expected the lease to be expiring soon, got %d leases", a)
+	}
+}
+
+// TestStoreCompactionMetrics verifies that the bytes read and written by
+// RocksDB compactions are exported per output level.
+func TestStoreCompactionMetrics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	store, stopper, _ := createTestStore(t)
+	defer stopper.Stop()
+
+	// Flush two versions of a key to overlapping sstables, so that compacting
+	// them has to rewrite them instead of moving them down the tree.
+	eng, ok := store.Engine().(engine.InMem)
+	if !ok {
+		t.Fatalf("unexpected engine type %T", store.Engine())
+	}
+	key := engine.MakeMVCCMetadataKey(roachpb.Key("a"))
+	for _, value := range []string{"old", "new"} {
+		if err := eng.Put(key, []byte(value)); err != nil {
+			t.Fatal(err)
+		}
+		if err := eng.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := eng.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ComputeMetrics(); err != nil {
+		t.Fatal(err)
+	}
+
+	var read, written int64
+	for i := 0; i < engine.NumLevels; i++ {
+		read += getGauge(t, store, fmt.Sprintf("rocksdb.compaction.bytes-read.l%d", i))
+		written += getGauge(t, store, fmt.Sprintf("rocksdb.compaction.bytes-written.l%d", i))
+	}
+	if read <= 0 {
+		t.Errorf("expected compactions to have read bytes, got %d", read)
+	}
+	if written <= 0 {
+		t.Errorf("expected compactions to have written bytes, got %d", written)
+	}
+}
+
+// TestStoreSplitAndMergeMetrics verifies that the durations of splits and
+// merges, as well as the range IDs allocated for splits, are recorded.
+func TestStoreSplitAndMergeMetrics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sCtx := storage.TestStoreContext()
+	sCtx.TestingKnobs.DisableSplitQueue = true
+	store, stopper, _ := createTestStoreWithContext(t, sCtx)
+	defer stopper.Stop()
+
+	rangeIDAllocs := getCounter(t, store, "kv.store.rangeid_alloc_per_second-count")
+	checkHistogramCount(t, store, "kv.range.split_duration_nanos-1h", 0)
+	args := adminSplitArgs(roachpb.KeyMin, roachpb.Key("b"))
+	if _, err := client.SendWrapped(rg1(store), nil, &args); err != nil {
+		t.Fatal(err)
+	}
+	checkHistogramCount(t, store, "kv.range.split_duration_nanos-1h", 1)
+	// The split allocated a range ID for the new right-hand range.
+	checkCounter(t, store, "kv.store.rangeid_alloc_per_second-count", rangeIDAllocs+1)
+
+	checkHistogramCount(t, store, "kv.range.merge_duration_nanos-1h", 0)
+	mArgs := adminMergeArgs(roachpb.KeyMin)
+	if _, err := client.SendWrapped(rg1(store), nil, &mArgs); err != nil {
+		t.Fatal(err)
+	}
+	checkHistogramCount(t, store, "kv.range.merge_duration_nanos-1h", 1)
+}
+
+// TestStoreRaftLogEntrySizeMetric verifies that the sizes of the entries
+// appended to the raft log are recorded.
+func TestStoreRaftLogEntrySizeMetric(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	store, stopper, _ := createTestStore(t)
+	defer stopper.Stop()
+
+	h := store.Registry().GetHistogram("raft.log.entry_size_bytes-1h")
+	if h == nil {
+		t.Fatal("store did not contain histogram raft.log.entry_size_bytes-1h")
+	}
+	entries := h.Current().TotalCount()
+
+	// The entry proposed for the put is appended before the put returns.
+	value := bytes.Repeat([]byte("v"), 1024)
+	pArgs := putArgs(roachpb.Key("a"), value)
+	if _, pErr := client.SendWrapped(rg1(store), nil, &pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if a := h.Current().TotalCount(); a <= entries {
+		t.Errorf("expected more than %d raft log entries, got %d", entries, a)
+	}
+	if a, e := h.Current().Max(), int64(len(value)); a < e {
+		t.Errorf("expected an entry of at least %d bytes, got %d", e, a)
+	}
+}
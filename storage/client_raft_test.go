@@ -1584,6 +1584,16 @@ func TestStoreRangeRebalance(t *testing.T) {
 		}
 	}
 
+	// The lease holder counts the replica it added as a rebalance once the
+	// replica change has completed.
+	util.SucceedsSoon(t, func() error {
+		if a := store0.Registry().GetCounter(
+			"kv.rebalance.range_rebalances_per_second-count").Count(); a < 1 {
+			return errors.Errorf("expected at least 1 rebalance, but found %d", a)
+		}
+		return nil
+	})
+
 	var generated int64
 	var normalApplied int64
 	var preemptiveApplied int64
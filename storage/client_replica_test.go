@@ -549,9 +549,12 @@ func TestRangeTransferLease(t *testing.T) {
 		return err
 	})
 
+	// Neither the no-op nor the invalid transfer above were recorded.
+	checkHistogramCount(t, mtc.stores[0], "kv.lease.transfer_duration_nanos-1h", 0)
 	if err := replica0.AdminTransferLease(newHolderDesc.StoreID); err != nil {
 		t.Fatal(err)
 	}
+	checkHistogramCount(t, mtc.stores[0], "kv.lease.transfer_duration_nanos-1h", 1)
 
 	// Check that replica0 doesn't serve reads any more.
 	replica0Desc, err = replica0.GetReplicaDescriptor()
@@ -205,6 +205,10 @@ type Batch interface {
 	Repr() []byte
 }
 
+// NumLevels is the number of levels in the RocksDB LSM tree. It must match
+// DB_NUM_LEVELS in rocksdb/db.h.
+const NumLevels = 7
+
 // Stats is a set of RocksDB stats. These are all described in RocksDB
 //
 // Currently, we collect stats from the following sources:
@@ -228,6 +232,17 @@ type Stats struct {
 	Flushes                  int64
 	Compactions              int64
 	TableReadersMemEstimate  int64
+	WALBytesWritten          int64
+	IteratorSeeks            int64
+	IteratorNexts            int64
+	WriteStallMicros         int64
+	PendingCompactionBytes   int64
+	// CompactionBytesWritten is indexed by the output level of the
+	// compactions.
+	CompactionBytesWritten [NumLevels]int64
+	// CompactionBytesRead is indexed by the output level of the compactions,
+	// matching the attribution used by RocksDB's own compaction stats.
+	CompactionBytesRead [NumLevels]int64
 }
 
 // PutProto sets the given key to the protobuf-serialized byte string
@@ -44,6 +44,7 @@ import (
 	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/stop"
 )
 
@@ -267,6 +268,10 @@ type RocksDB struct {
 	maxOpenFiles   int                // The maximum number of open files this instance will use.
 	stopper        *stop.Stopper
 	deallocated    chan struct{} // Closed when the underlying handle is deallocated.
+
+	// writeBatchSizes, if set, records the size in bytes of every committed
+	// batch. See SetWriteBatchSizes.
+	writeBatchSizes metric.Histograms
 }
 
 var _ Engine = &RocksDB{}
@@ -589,6 +594,13 @@ func (r *RocksDB) NewBatch() Batch {
 	return newRocksDBBatch(r)
 }
 
+// SetWriteBatchSizes sets the histograms which record the size in bytes of
+// every batch committed to the engine. It must be called before the engine is
+// used concurrently.
+func (r *RocksDB) SetWriteBatchSizes(h metric.Histograms) {
+	r.writeBatchSizes = h
+}
+
 // GetSSTables retrieves metadata about this engine's live sstables.
 func (r *RocksDB) GetSSTables() SSTableInfos {
 	var n C.int
@@ -625,7 +637,7 @@ func (r *RocksDB) GetStats() (*Stats, error) {
 	if err := statusToError(C.DBGetStats(r.rdb, &s)); err != nil {
 		return nil, err
 	}
-	return &Stats{
+	stats := &Stats{
 		BlockCacheHits:           int64(s.block_cache_hits),
 		BlockCacheMisses:         int64(s.block_cache_misses),
 		BlockCacheUsage:          int64(s.block_cache_usage),
@@ -638,7 +650,17 @@ func (r *RocksDB) GetStats() (*Stats, error) {
 		Flushes:                  int64(s.flushes),
 		Compactions:              int64(s.compactions),
 		TableReadersMemEstimate:  int64(s.table_readers_mem_estimate),
-	}, nil
+		WALBytesWritten:          int64(s.wal_bytes_written),
+		IteratorSeeks:            int64(s.iterator_seeks),
+		IteratorNexts:            int64(s.iterator_nexts),
+		WriteStallMicros:         int64(s.write_stall_micros),
+		PendingCompactionBytes:   int64(s.pending_compaction_bytes),
+	}
+	for i := range stats.CompactionBytesWritten {
+		stats.CompactionBytesWritten[i] = int64(s.compaction_bytes_written[i])
+		stats.CompactionBytesRead[i] = int64(s.compaction_bytes_read[i])
+	}
+	return stats, nil
 }
 
 type rocksDBSnapshot struct {
@@ -854,6 +876,7 @@ type rocksDBBatch struct {
 	parent             *RocksDB
 	batch              *C.DBEngine
 	flushes            int
+	flushedBytes       int
 	prefixIter         rocksDBBatchIterator
 	normalIter         rocksDBBatchIterator
 	builder            rocksDBBatchBuilder
@@ -986,12 +1009,15 @@ func (r *rocksDBBatch) Commit() error {
 		if err := statusToError(C.DBCommitBatch(r.batch)); err != nil {
 			return err
 		}
+		r.parent.writeBatchSizes.RecordValue(int64(r.flushedBytes))
 	} else if r.builder.count > 0 {
 		// Fast-path which avoids flushing mutations to the C++ batch. Instead, we
 		// directly apply the mutations to the database.
-		if err := r.parent.ApplyBatchRepr(r.builder.Finish()); err != nil {
+		repr := r.builder.Finish()
+		if err := r.parent.ApplyBatchRepr(repr); err != nil {
 			return err
 		}
+		r.parent.writeBatchSizes.RecordValue(int64(len(repr)))
 	}
 
 	C.DBClose(r.batch)
@@ -1022,7 +1048,11 @@ func (r *rocksDBBatch) flushMutations() {
 	}
 	r.distinctNeedsFlush = false
 	r.flushes++
-	if err := r.ApplyBatchRepr(r.builder.Finish()); err != nil {
+	repr := r.builder.Finish()
+	// The batch header is counted once per flush, which slightly overstates
+	// the final size of batches flushed more than once.
+	r.flushedBytes += len(repr)
+	if err := r.ApplyBatchRepr(repr); err != nil {
 		panic(err)
 	}
 	// Force a seek of the underlying iterator on the next Seek/ReverseSeek.
@@ -1672,18 +1672,26 @@ DBIterator* DBSnapshot::NewIter(bool prefix) {
   return iter;
 }
 
+namespace {
+
+// GetIntProperty returns the value of the given integer-valued property
+// of the DB, or 0 if the property is not available.
+int64_t GetIntProperty(rocksdb::DB* db, const rocksdb::Slice& property) {
+  std::string value;
+  if (!db->GetProperty(property, &value) || value.empty()) {
+    return 0;
+  }
+  return std::stoll(value);
+}
+
+}  // namespace
+
 // GetStats retrieves a subset of RocksDB stats that are relevant to
 // CockroachDB.
 DBStatus DBImpl::GetStats(DBStatsResult* stats) {
   const rocksdb::Options &opts = rep->GetOptions();
   const std::shared_ptr<rocksdb::Statistics> &s = opts.statistics;
 
-  std::string memtable_total_size;
-  rep->GetProperty("rocksdb.cur-size-all-mem-tables", &memtable_total_size);
-
-  std::string table_readers_mem_estimate;
-  rep->GetProperty("rocksdb.estimate-table-readers-mem", &table_readers_mem_estimate);
-
   stats->block_cache_hits = (int64_t)s->getTickerCount(rocksdb::BLOCK_CACHE_HIT);
   stats->block_cache_misses = (int64_t)s->getTickerCount(rocksdb::BLOCK_CACHE_MISS);
   stats->block_cache_usage = (int64_t)block_cache->GetUsage();
@@ -1694,10 +1702,23 @@ DBStatus DBImpl::GetStats(DBStatsResult* stats) {
     (int64_t)s->getTickerCount(rocksdb::BLOOM_FILTER_PREFIX_USEFUL);
   stats->memtable_hits = (int64_t)s->getTickerCount(rocksdb::MEMTABLE_HIT);
   stats->memtable_misses = (int64_t)s->getTickerCount(rocksdb::MEMTABLE_MISS);
-  stats->memtable_total_size = std::stoll(memtable_total_size);
+  stats->memtable_total_size = GetIntProperty(rep, "rocksdb.cur-size-all-mem-tables");
   stats->flushes = (int64_t)event_listener->GetFlushes();
   stats->compactions = (int64_t)event_listener->GetCompactions();
-  stats->table_readers_mem_estimate = std::stoll(table_readers_mem_estimate);
+  stats->table_readers_mem_estimate =
+    GetIntProperty(rep, "rocksdb.estimate-table-readers-mem");
+  stats->wal_bytes_written = (int64_t)s->getTickerCount(rocksdb::WAL_FILE_BYTES);
+  stats->iterator_seeks = (int64_t)s->getTickerCount(rocksdb::NUMBER_DB_SEEK);
+  stats->iterator_nexts = (int64_t)s->getTickerCount(rocksdb::NUMBER_DB_NEXT);
+  stats->write_stall_micros = (int64_t)s->getTickerCount(rocksdb::STALL_MICROS);
+  stats->pending_compaction_bytes =
+    GetIntProperty(rep, "rocksdb.estimate-pending-compaction-bytes");
+  for (int i = 0; i < DB_NUM_LEVELS; i++) {
+    stats->compaction_bytes_written[i] =
+      (int64_t)event_listener->GetCompactionBytesWritten(i);
+    stats->compaction_bytes_read[i] =
+      (int64_t)event_listener->GetCompactionBytesRead(i);
+  }
   return kSuccess;
 }
 
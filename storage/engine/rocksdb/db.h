@@ -20,6 +20,10 @@
 #include <stdbool.h>
 #include <stdint.h>
 
+// DB_NUM_LEVELS is the number of levels in the LSM tree. It matches the
+// RocksDB default for num_levels, which we do not override.
+#define DB_NUM_LEVELS 7
+
 #ifdef __cplusplus
 extern "C" {
 #endif
@@ -217,6 +221,13 @@ typedef struct {
   int64_t flushes;
   int64_t compactions;
   int64_t table_readers_mem_estimate;
+  int64_t wal_bytes_written;
+  int64_t iterator_seeks;
+  int64_t iterator_nexts;
+  int64_t write_stall_micros;
+  int64_t pending_compaction_bytes;
+  int64_t compaction_bytes_written[DB_NUM_LEVELS];
+  int64_t compaction_bytes_read[DB_NUM_LEVELS];
 } DBStatsResult;
 
 DBStatus DBGetStats(DBEngine* db, DBStatsResult* stats);
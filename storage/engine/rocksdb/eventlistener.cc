@@ -22,6 +22,10 @@ static const bool kDebug = false;
 DBEventListener::DBEventListener()
   : flushes_(0),
     compactions_(0) {
+  for (int i = 0; i < DB_NUM_LEVELS; i++) {
+    compaction_bytes_written_[i] = 0;
+    compaction_bytes_read_[i] = 0;
+  }
 }
 
 void DBEventListener::OnFlushCompleted(rocksdb::DB* db, const rocksdb::FlushJobInfo& flush_job_info) {
@@ -38,6 +42,10 @@ void DBEventListener::OnFlushCompleted(rocksdb::DB* db, const rocksdb::FlushJobI
 
 void DBEventListener::OnCompactionCompleted(rocksdb::DB* db, const rocksdb::CompactionJobInfo& ci) {
   ++compactions_;
+  if (ci.output_level >= 0 && ci.output_level < DB_NUM_LEVELS) {
+    compaction_bytes_written_[ci.output_level] += ci.stats.total_output_bytes;
+    compaction_bytes_read_[ci.output_level] += ci.stats.total_input_bytes;
+  }
 
   if (kDebug) {
     fprintf(stderr, "OnCompactionCompleted:\n");
@@ -58,3 +66,11 @@ uint64_t DBEventListener::GetFlushes() const {
 uint64_t DBEventListener::GetCompactions() const {
   return compactions_.load();
 }
+
+uint64_t DBEventListener::GetCompactionBytesWritten(int level) const {
+  return compaction_bytes_written_[level].load();
+}
+
+uint64_t DBEventListener::GetCompactionBytesRead(int level) const {
+  return compaction_bytes_read_[level].load();
+}
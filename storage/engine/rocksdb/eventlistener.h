@@ -20,6 +20,7 @@
 #include <atomic>
 
 #include <rocksdb/db.h>
+#include "db.h"
 
 // DBEventListener is an implementation of RocksDB's EventListener interface
 // used to collect information on RocksDB events that could be of interest
@@ -31,6 +32,12 @@ class DBEventListener : public rocksdb::EventListener {
 
   uint64_t GetFlushes() const;
   uint64_t GetCompactions() const;
+  // GetCompactionBytesWritten returns the number of bytes written by
+  // compactions whose output was the given level.
+  uint64_t GetCompactionBytesWritten(int level) const;
+  // GetCompactionBytesRead returns the number of bytes read by compactions
+  // whose output was the given level.
+  uint64_t GetCompactionBytesRead(int level) const;
 
   // EventListener methods.
   virtual void OnFlushCompleted(rocksdb::DB* db, const rocksdb::FlushJobInfo& flush_job_info) override;
@@ -39,6 +46,8 @@ class DBEventListener : public rocksdb::EventListener {
  private:
   std::atomic<uint64_t> flushes_;
   std::atomic<uint64_t> compactions_;
+  std::atomic<uint64_t> compaction_bytes_written_[DB_NUM_LEVELS];
+  std::atomic<uint64_t> compaction_bytes_read_[DB_NUM_LEVELS];
 };
 
 
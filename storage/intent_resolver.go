@@ -18,6 +18,8 @@
 package storage
 
 import (
+	"strings"
+
 	"github.com/cockroachdb/cockroach/base"
 	"github.com/cockroachdb/cockroach/internal/client"
 	"github.com/cockroachdb/cockroach/keys"
@@ -205,6 +207,11 @@ func (ir *intentResolver) maybePushTransactions(
 			nonPendingIntents))
 	}
 
+	if len(pushIntents) > 0 {
+		ir.store.metrics.txnPushCount.WithLabelValues(
+			strings.ToLower(pushType.String())).Inc(int64(len(pushIntents)))
+	}
+
 	// Attempt to push the transaction(s) which created the conflicting intent(s).
 	var pushReqs []roachpb.Request
 	for _, intent := range pushIntents {
@@ -401,6 +408,7 @@ func (ir *intentResolver) resolveIntents(ctx context.Context,
 
 	// Resolve all of the intents.
 	if len(reqs) > 0 {
+		ir.store.metrics.intentResolveBatchSize.RecordValue(int64(len(reqs)))
 		b := &client.Batch{}
 		b.AddRawRequest(reqs...)
 		action := func() error {
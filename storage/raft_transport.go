@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/roachpb"
 	"github.com/cockroachdb/cockroach/rpc"
 	"github.com/cockroachdb/cockroach/util/log"
+	"github.com/cockroachdb/cockroach/util/metric"
 	"github.com/cockroachdb/cockroach/util/syncutil"
 	"github.com/cockroachdb/cockroach/util/timeutil"
 	"github.com/rubyist/circuitbreaker"
@@ -45,8 +46,19 @@ const (
 	// TODO(tamird): make culling of outbound streams more evented, so that we
 	// need not rely on this timeout to shut things down.
 	raftIdleTimeout = time.Minute
+
+	// raftStreamName labels the RaftMessage stream in the RPC stream metrics.
+	raftStreamName = "raft"
 )
 
+// MetricSnapshotsQueuedName is the name of the gauge of snapshots waiting in
+// the outgoing queues of the raft transport.
+const MetricSnapshotsQueuedName = "kv.range.snapshots_queued"
+
+// MetricSnapshotBytesSentName is the name of the counter of bytes written to
+// raft streams in snapshots. Snapshots whose send fails are included.
+const MetricSnapshotBytesSentName = "raft.snapshot.bytes_sent"
+
 type raftMessageHandler func(*RaftMessageRequest) error
 
 // NodeAddressResolver is the function used by RaftTransport to map node IDs to
@@ -85,6 +97,9 @@ type RaftTransport struct {
 	rpcContext         *rpc.Context
 	SnapshotStatusChan chan RaftSnapshotStatus
 
+	snapshotsQueued   *metric.Gauge
+	snapshotBytesSent *metric.Counter
+
 	mu struct {
 		syncutil.Mutex
 		handlers map[roachpb.StoreID]raftMessageHandler
@@ -106,6 +121,8 @@ func NewRaftTransport(resolver NodeAddressResolver, grpcServer *grpc.Server, rpc
 		resolver:           resolver,
 		rpcContext:         rpcContext,
 		SnapshotStatusChan: make(chan RaftSnapshotStatus),
+		snapshotsQueued:    metric.NewGauge(),
+		snapshotBytesSent:  metric.NewCounter(),
 	}
 	t.mu.handlers = make(map[roachpb.StoreID]raftMessageHandler)
 	t.mu.queues = make(map[bool]map[roachpb.ReplicaIdent]chan *RaftMessageRequest)
@@ -118,9 +135,16 @@ func NewRaftTransport(resolver NodeAddressResolver, grpcServer *grpc.Server, rpc
 	return t
 }
 
+// RegisterMetrics adds the metrics of the transport to a registry.
+func (t *RaftTransport) RegisterMetrics(reg *metric.Registry) {
+	reg.MustAdd(MetricSnapshotsQueuedName, t.snapshotsQueued)
+	reg.MustAdd(MetricSnapshotBytesSentName, t.snapshotBytesSent)
+}
+
 // RaftMessage proxies the incoming request to the listening server interface.
 func (t *RaftTransport) RaftMessage(stream MultiRaft_RaftMessageServer) (err error) {
 	errCh := make(chan error, 1)
+	received := t.rpcContext.StreamMessagesReceived(raftStreamName)
 
 	// Node stopping error is caught below in the select.
 	if err := t.rpcContext.Stopper.RunTask(func() {
@@ -131,6 +155,7 @@ func (t *RaftTransport) RaftMessage(stream MultiRaft_RaftMessageServer) (err err
 					if err != nil {
 						return err
 					}
+					received.Inc(1)
 
 					t.mu.Lock()
 					handler, ok := t.mu.handlers[req.ToReplica.StoreID]
@@ -242,6 +267,7 @@ func (t *RaftTransport) processQueue(ch chan *RaftMessageRequest, conn *grpc.Cli
 	}
 
 	errCh := make(chan error, 1)
+	sent := t.rpcContext.StreamMessagesSent(raftStreamName)
 
 	// Starting workers in a task prevents data races during shutdown.
 	if err := t.rpcContext.Stopper.RunTask(func() {
@@ -265,8 +291,12 @@ func (t *RaftTransport) processQueue(ch chan *RaftMessageRequest, conn *grpc.Cli
 		case err := <-errCh:
 			return err
 		case req := <-ch:
+			if req.Message.Type == raftpb.MsgSnap {
+				t.snapshotsQueued.Dec(1)
+			}
 			err := stream.Send(req)
 			if req.Message.Type == raftpb.MsgSnap {
+				t.snapshotBytesSent.Inc(int64(req.Size()))
 				select {
 				case <-t.rpcContext.Stopper.ShouldStop():
 					return nil
@@ -277,6 +307,7 @@ func (t *RaftTransport) processQueue(ch chan *RaftMessageRequest, conn *grpc.Cli
 			if err != nil {
 				return err
 			}
+			sent.Inc(1)
 		}
 	}
 }
@@ -347,6 +378,9 @@ func (s RaftSender) SendAsync(req *RaftMessageRequest) bool {
 
 				s.transport.mu.Lock()
 				delete(queues, toReplicaIdent)
+				if isSnap {
+					s.transport.drainSnapshotQueueLocked(ch)
+				}
 				s.transport.mu.Unlock()
 			})
 		}); err != nil {
@@ -354,10 +388,44 @@ func (s RaftSender) SendAsync(req *RaftMessageRequest) bool {
 		}
 	}
 
+	if !isSnap {
+		select {
+		case ch <- req:
+			return true
+		default:
+			return false
+		}
+	}
+
+	// Snapshots are enqueued under the lock so that they can't end up in a
+	// queue that has already been drained by its exiting processQueue, and
+	// are counted before they become visible to processQueue.
+	s.transport.mu.Lock()
+	defer s.transport.mu.Unlock()
+	if queues[toReplicaIdent] != ch {
+		return false
+	}
+	s.transport.snapshotsQueued.Inc(1)
 	select {
 	case ch <- req:
 		return true
 	default:
+		s.transport.snapshotsQueued.Dec(1)
 		return false
 	}
 }
+
+// drainSnapshotQueueLocked discards the snapshots left in a queue whose
+// instance of processQueue has exited, so that they are no longer counted as
+// queued. The messages would be lost anyway. The queue must already have been
+// removed from t.mu.queues.
+func (t *RaftTransport) drainSnapshotQueueLocked(ch chan *RaftMessageRequest) {
+	for {
+		select {
+		case <-ch:
+			t.snapshotsQueued.Dec(1)
+		default:
+			return
+		}
+	}
+}
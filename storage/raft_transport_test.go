@@ -497,3 +497,133 @@ func TestRaftTransportIndependentRanges(t *testing.T) {
 		}
 	}
 }
+
+// TestRaftTransportSnapshotsQueued verifies that the snapshots queued gauge
+// counts only snapshots that made it into the outgoing queue and drops back
+// to zero once the queue is discarded.
+func TestRaftTransportSnapshotsQueued(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	rttc := newRaftTransportTestContext(t)
+	defer rttc.Stop()
+
+	server := roachpb.ReplicaDescriptor{
+		NodeID:    1,
+		StoreID:   1,
+		ReplicaID: 1,
+	}
+	serverTransport := rttc.AddNode(server.NodeID)
+	client := roachpb.ReplicaDescriptor{
+		NodeID:    2,
+		StoreID:   2,
+		ReplicaID: 2,
+	}
+	clientTransport := rttc.AddNode(client.NodeID)
+
+	reg := metric.NewRegistry()
+	clientTransport.RegisterMetrics(reg)
+	queued := reg.GetGauge(storage.MetricSnapshotsQueuedName)
+
+	// The server rejects all messages to the range, which closes the stream
+	// after the first snapshot.
+	const rangeID = 13
+	channelServer := newChannelServer(10, 0)
+	channelServer.brokenRange = rangeID
+	serverTransport.Listen(server.StoreID, channelServer.RaftMessage)
+
+	sender := clientTransport.MakeSender(func(error, roachpb.ReplicaDescriptor) {})
+	req := &storage.RaftMessageRequest{
+		RangeID:     rangeID,
+		Message:     raftpb.Message{Type: raftpb.MsgSnap},
+		ToReplica:   server,
+		FromReplica: client,
+	}
+
+	// Nobody reads the client's SnapshotStatusChan yet, so processQueue
+	// blocks after taking the first snapshot and the queue fills up.
+	var accepted int64
+	for i := 0; sender.SendAsync(req); i++ {
+		if i > 1000 {
+			t.Fatal("outgoing snapshot queue never filled up")
+		}
+		accepted++
+	}
+	util.SucceedsSoon(t, func() error {
+		if v := queued.Value(); v != accepted-1 {
+			return errors.Errorf("expected %d queued snapshots, got %d", accepted-1, v)
+		}
+		return nil
+	})
+
+	// Once the status of the first snapshot is consumed, processQueue notices
+	// the closed stream and exits, and the remaining snapshots are discarded.
+	rttc.stopper.RunWorker(func() {
+		for {
+			select {
+			case <-clientTransport.SnapshotStatusChan:
+			case <-rttc.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+	util.SucceedsSoon(t, func() error {
+		if v := queued.Value(); v != 0 {
+			return errors.Errorf("expected no queued snapshots, got %d", v)
+		}
+		return nil
+	})
+}
+
+// TestRaftTransportSnapshotBytesSent verifies that the bytes of the snapshots
+// written to the raft stream are counted.
+func TestRaftTransportSnapshotBytesSent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	rttc := newRaftTransportTestContext(t)
+	defer rttc.Stop()
+
+	server := roachpb.ReplicaDescriptor{
+		NodeID:    1,
+		StoreID:   1,
+		ReplicaID: 1,
+	}
+	serverTransport := rttc.AddNode(server.NodeID)
+	client := roachpb.ReplicaDescriptor{
+		NodeID:    2,
+		StoreID:   2,
+		ReplicaID: 2,
+	}
+	clientTransport := rttc.AddNode(client.NodeID)
+
+	reg := metric.NewRegistry()
+	clientTransport.RegisterMetrics(reg)
+	bytesSent := reg.GetCounter(storage.MetricSnapshotBytesSentName)
+
+	channelServer := newChannelServer(1, 0)
+	serverTransport.Listen(server.StoreID, channelServer.RaftMessage)
+
+	sender := clientTransport.MakeSender(func(error, roachpb.ReplicaDescriptor) {})
+	req := &storage.RaftMessageRequest{
+		RangeID: 1,
+		Message: raftpb.Message{
+			Type:     raftpb.MsgSnap,
+			Snapshot: raftpb.Snapshot{Data: make([]byte, 1024)},
+		},
+		ToReplica:   server,
+		FromReplica: client,
+	}
+	if !sender.SendAsync(req) {
+		t.Fatal("failed to send snapshot")
+	}
+
+	// The bytes are counted before the status of the snapshot is reported.
+	select {
+	case st := <-clientTransport.SnapshotStatusChan:
+		if st.Err != nil {
+			t.Fatal(st.Err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for snapshot status")
+	}
+	if a, e := bytesSent.Count(), int64(req.Size()); a != e {
+		t.Errorf("expected %d snapshot bytes sent, got %d", e, a)
+	}
+}
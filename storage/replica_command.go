@@ -1191,10 +1191,16 @@ func (r *Replica) GC(
 
 	var reply roachpb.GCResponse
 	// Garbage collect the specified keys by expiration timestamps.
+	origMS := *ms
 	err := engine.MVCCGarbageCollect(ctx, batch, ms, keys, h.Timestamp)
 	if err != nil {
 		return reply, nil, err
 	}
+	// The stats deltas of the collection are negative; record them as the
+	// number of bytes and key-value pairs freed.
+	bytesFreed := (origMS.KeyBytes + origMS.ValBytes + origMS.SysBytes) -
+		(ms.KeyBytes + ms.ValBytes + ms.SysBytes)
+	keysFreed := (origMS.ValCount + origMS.SysCount) - (ms.ValCount + ms.SysCount)
 
 	r.mu.Lock()
 	newThreshold := r.mu.state.GCThreshold
@@ -1202,9 +1208,14 @@ func (r *Replica) GC(
 	r.mu.Unlock()
 
 	trigger := &PostCommitTrigger{
-		gcThreshold: &newThreshold,
+		gcThreshold:  &newThreshold,
+		gcBytesFreed: bytesFreed,
+		gcKeysFreed:  keysFreed,
 	}
-	return reply, trigger, setGCThreshold(ctx, batch, ms, r.Desc().RangeID, &newThreshold)
+	if err := setGCThreshold(ctx, batch, ms, r.Desc().RangeID, &newThreshold); err != nil {
+		return reply, nil, err
+	}
+	return reply, trigger, nil
 }
 
 // PushTxn resolves conflicts between concurrent txns (or
@@ -2214,6 +2225,7 @@ func (r *Replica) AdminSplit(
 	leftDesc.EndKey = splitKey
 
 	log.Infof(ctx, "%s: initiating a split of this range at key %s", r, splitKey)
+	splitStart := timeutil.Now()
 
 	if err := r.store.DB().Txn(context.TODO(), func(txn *client.Txn) error {
 		log.Trace(ctx, "split closure begins")
@@ -2262,6 +2274,7 @@ func (r *Replica) AdminSplit(
 	}); err != nil {
 		return reply, roachpb.NewErrorf("split at key %s failed: %s", splitKey, err)
 	}
+	r.store.metrics.rangeSplitDurationNanos.RecordValue(timeutil.Since(splitStart).Nanoseconds())
 
 	return reply, nil
 }
@@ -2652,6 +2665,7 @@ func (r *Replica) AdminMerge(
 		log.Infof(ctx, "%s: initiating a merge of %s into this range", r, rightRng)
 	}
 
+	mergeStart := timeutil.Now()
 	if err := r.store.DB().Txn(context.TODO(), func(txn *client.Txn) error {
 		log.Trace(ctx, "merge closure begins")
 		// Update the range descriptor for the receiving range.
@@ -2714,6 +2728,7 @@ func (r *Replica) AdminMerge(
 	}); err != nil {
 		return reply, roachpb.NewErrorf("merge of range into %d failed: %s", origLeftDesc.RangeID, err)
 	}
+	r.store.metrics.rangeMergeDurationNanos.RecordValue(timeutil.Since(mergeStart).Nanoseconds())
 
 	return reply, nil
 }
@@ -504,6 +504,7 @@ func (r *Replica) append(
 		if err := engine.MVCCPutProto(ctx, batch, &diff, key, hlc.ZeroTimestamp, nil /* txn */, ent); err != nil {
 			return 0, 0, err
 		}
+		r.store.metrics.raftLogEntrySize.RecordValue(int64(ent.Size()))
 	}
 	lastIndex := entries[len(entries)-1].Index
 	// Delete any previously appended log entries which never committed.
@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/util/hlc"
 	"github.com/cockroachdb/cockroach/util/log"
 	"github.com/cockroachdb/cockroach/util/protoutil"
+	"github.com/cockroachdb/cockroach/util/timeutil"
 	"github.com/pkg/errors"
 )
 
@@ -264,6 +265,7 @@ func (r *Replica) requestLeaseLocked(timestamp hlc.Timestamp) <-chan *roachpb.Er
 // TODO(andrei): figure out how to persist the "not serving" state across node
 // restarts.
 func (r *Replica) AdminTransferLease(target roachpb.StoreID) error {
+	start := timeutil.Now()
 	// initTransferHelper inits a transfer if no extension is in progress.
 	// It returns a channel for waiting for the result of a pending
 	// extension (if any is in progress) and a channel for waiting for the
@@ -328,7 +330,11 @@ func (r *Replica) AdminTransferLease(target roachpb.StoreID) error {
 				// The target is us and we're the lease holder.
 				return nil
 			}
-			return (<-transfer).GoError()
+			if pErr := <-transfer; pErr != nil {
+				return pErr.GoError()
+			}
+			r.store.metrics.leaseTransferDurationNanos.RecordValue(timeutil.Since(start).Nanoseconds())
+			return nil
 		}
 		// Wait for the in-progress extension without holding the mutex.
 		if r.store.TestingKnobs().LeaseTransferBlockedOnExtensionEvent != nil {
@@ -90,6 +90,12 @@ type PostCommitTrigger struct {
 	leaseMetricsResult *bool // increase success or error lease counter
 	lease              *roachpb.Lease
 
+	// gcBytesFreed and gcKeysFreed are the number of bytes and key-value
+	// pairs removed by GC requests, which are added to the store's GC
+	// metrics once the batch has been committed.
+	gcBytesFreed int64
+	gcKeysFreed  int64
+
 	gossipFirstRange        bool
 	maybeGossipSystemConfig bool
 	maybeAddToSplitQueue    bool
@@ -148,6 +154,8 @@ func updateTrigger(old, new *PostCommitTrigger) *PostCommitTrigger {
 		if new.leaseMetricsResult != nil {
 			old.leaseMetricsResult = new.leaseMetricsResult
 		}
+		old.gcBytesFreed += new.gcBytesFreed
+		old.gcKeysFreed += new.gcKeysFreed
 
 		if new.gossipFirstRange {
 			old.gossipFirstRange = true
@@ -433,6 +441,10 @@ func (r *Replica) handleTrigger(
 	if trigger.leaseMetricsResult != nil {
 		r.store.metrics.leaseRequestComplete(*trigger.leaseMetricsResult)
 	}
+	if trigger.gcBytesFreed != 0 || trigger.gcKeysFreed != 0 {
+		r.store.metrics.gcBytesFreed.Inc(trigger.gcBytesFreed)
+		r.store.metrics.gcKeysFreed.Inc(trigger.gcKeysFreed)
+	}
 
 	if trigger.gossipFirstRange {
 		// We need to run the gossip in an async task because gossiping requires
@@ -205,6 +205,7 @@ func (rq *replicateQueue) process(
 		if err = repl.ChangeReplicas(ctx, roachpb.ADD_REPLICA, rebalanceReplica, desc); err != nil {
 			return err
 		}
+		repl.store.metrics.rangeRebalances.Add(1)
 	}
 
 	// Enqueue this replica again to see if there are more changes to be made.
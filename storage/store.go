@@ -529,6 +529,10 @@ type StoreContext struct {
 	// period) during which operations will trigger an asynchronous renewal of the
 	// lease.
 	rangeLeaseRenewalDuration time.Duration
+
+	// leaseExpiringSoonWindow is the time before the start of their stasis
+	// period at which leases held by the store are counted as expiring soon.
+	leaseExpiringSoonWindow time.Duration
 }
 
 // StoreTestingKnobs is a part of the context used to control parts of the system.
@@ -588,6 +592,12 @@ type storeMetrics struct {
 	// Lease data metrics.
 	leaseRequestSuccessCount *metric.Counter
 	leaseRequestErrorCount   *metric.Counter
+	leaseExpiringSoonCount   *metric.Gauge
+
+	// leaseTransferDurationNanos records the time taken by successful lease
+	// transfers initiated by this store, including time spent waiting for an
+	// in-progress extension.
+	leaseTransferDurationNanos metric.Histograms
 
 	// Storage metrics.
 	liveBytes       *metric.Gauge
@@ -612,8 +622,10 @@ type storeMetrics struct {
 	rdbBlockCacheMisses         *metric.Gauge
 	rdbBlockCacheUsage          *metric.Gauge
 	rdbBlockCachePinnedUsage    *metric.Gauge
+	rdbBlockCacheHitRate        *metric.GaugeFloat64
 	rdbBloomFilterPrefixChecked *metric.Gauge
 	rdbBloomFilterPrefixUseful  *metric.Gauge
+	rdbBloomFilterHitRate       *metric.GaugeFloat64
 	rdbMemtableHits             *metric.Gauge
 	rdbMemtableMisses           *metric.Gauge
 	rdbMemtableTotalSize        *metric.Gauge
@@ -621,6 +633,19 @@ type storeMetrics struct {
 	rdbCompactions              *metric.Gauge
 	rdbTableReadersMemEstimate  *metric.Gauge
 	rdbReadAmplification        *metric.Gauge
+	rdbWALBytesWritten          *metric.Gauge
+	rdbIteratorSeeks            *metric.Gauge
+	rdbIteratorNexts            *metric.Gauge
+	rdbPendingCompactionBytes   *metric.Gauge
+	rdbCompactionBytesWritten   [engine.NumLevels]*metric.Gauge
+	rdbCompactionBytesRead      [engine.NumLevels]*metric.Gauge
+	rdbWriteBatchSize           metric.Histograms
+
+	// RocksDB only exposes the cumulative time writes spent stalled, so stalls
+	// within the same stats interval are counted as one, and their total time
+	// is recorded as the duration of that stall.
+	rdbWriteStallCount         *metric.Counter
+	rdbWriteStallDurationNanos metric.Histograms
 
 	// Range event metrics.
 	rangeSplits                     *metric.Counter
@@ -629,11 +654,25 @@ type storeMetrics struct {
 	rangeSnapshotsGenerated         *metric.Counter
 	rangeSnapshotsNormalApplied     *metric.Counter
 	rangeSnapshotsPreemptiveApplied *metric.Counter
+	rangeSplitDurationNanos         metric.Histograms
+	rangeMergeDurationNanos         metric.Histograms
+	rangeIDAllocations              metric.Rates
+	rangeRebalances                 metric.Rates
+
+	// GC metrics.
+	gcBytesFreed *metric.Counter
+	gcKeysFreed  *metric.Counter
+
+	// Intent resolver metrics.
+	txnPushCount           *metric.LabeledCounter // Labeled by push type.
+	intentResolveBatchSize metric.Histograms
 
 	// Raft processing metrics.
 	raftSelectDurationNanos  *metric.Counter
 	raftWorkingDurationNanos *metric.Counter
 	raftTickingDurationNanos *metric.Counter
+	raftLogBehindCount       *metric.Gauge // Summed over all followers of ranges led by this store.
+	raftLogEntrySize         metric.Histograms
 
 	// Stats for efficient merges.
 	// TODO(mrtracy): This should be removed as part of #4465. This is only
@@ -642,11 +681,22 @@ type storeMetrics struct {
 	// accordingly.
 	mu    syncutil.Mutex
 	stats enginepb.MVCCStats
+	// lastWriteStallMicros is the cumulative RocksDB stall time seen by the
+	// previous call to updateRocksDBStats.
+	lastWriteStallMicros int64
 }
 
+// maxWriteBatchSize is four times the default range size, leaving headroom
+// for batches that rewrite a whole range.
+const maxWriteBatchSize = 256 << 20 // 256 MB
+
+// maxRaftLogEntrySize is the default range size, which one command should not
+// exceed.
+const maxRaftLogEntrySize = 64 << 20 // 64 MB
+
 func newStoreMetrics() *storeMetrics {
 	storeRegistry := metric.NewRegistry()
-	return &storeMetrics{
+	sm := &storeMetrics{
 		registry:                     storeRegistry,
 		replicaCount:                 storeRegistry.Counter("replicas"),
 		reservedReplicaCount:         storeRegistry.Counter("replicas.reserved"),
@@ -656,6 +706,8 @@ func newStoreMetrics() *storeMetrics {
 		availableRangeCount:          storeRegistry.Gauge("ranges.available"),
 		leaseRequestSuccessCount:     storeRegistry.Counter("leases.success"),
 		leaseRequestErrorCount:       storeRegistry.Counter("leases.error"),
+		leaseExpiringSoonCount:       storeRegistry.Gauge("kv.range.lease_expiry_soon_count"),
+		leaseTransferDurationNanos:   storeRegistry.Latency("kv.lease.transfer_duration_nanos"),
 		liveBytes:                    storeRegistry.Gauge("livebytes"),
 		keyBytes:                     storeRegistry.Gauge("keybytes"),
 		valBytes:                     storeRegistry.Gauge("valbytes"),
@@ -678,8 +730,10 @@ func newStoreMetrics() *storeMetrics {
 		rdbBlockCacheMisses:         storeRegistry.Gauge("rocksdb.block.cache.misses"),
 		rdbBlockCacheUsage:          storeRegistry.Gauge("rocksdb.block.cache.usage"),
 		rdbBlockCachePinnedUsage:    storeRegistry.Gauge("rocksdb.block.cache.pinned-usage"),
+		rdbBlockCacheHitRate:        storeRegistry.GaugeFloat64("storage.engine.block_cache_hit_rate"),
 		rdbBloomFilterPrefixChecked: storeRegistry.Gauge("rocksdb.bloom.filter.prefix.checked"),
 		rdbBloomFilterPrefixUseful:  storeRegistry.Gauge("rocksdb.bloom.filter.prefix.useful"),
+		rdbBloomFilterHitRate:       storeRegistry.GaugeFloat64("storage.bloom_filter.hit_rate"),
 		rdbMemtableHits:             storeRegistry.Gauge("rocksdb.memtable.hits"),
 		rdbMemtableMisses:           storeRegistry.Gauge("rocksdb.memtable.misses"),
 		rdbMemtableTotalSize:        storeRegistry.Gauge("rocksdb.memtable.total-size"),
@@ -687,6 +741,14 @@ func newStoreMetrics() *storeMetrics {
 		rdbCompactions:              storeRegistry.Gauge("rocksdb.compactions"),
 		rdbTableReadersMemEstimate:  storeRegistry.Gauge("rocksdb.table-readers-mem-estimate"),
 		rdbReadAmplification:        storeRegistry.Gauge("rocksdb.read-amplification"),
+		rdbWALBytesWritten:          storeRegistry.Gauge("storage.wal.bytes_written"),
+		rdbIteratorSeeks:            storeRegistry.Gauge("storage.iterator.seek_count"),
+		rdbIteratorNexts:            storeRegistry.Gauge("storage.iterator.next_count"),
+		rdbPendingCompactionBytes:   storeRegistry.Gauge("storage.pending_compaction_bytes"),
+		rdbWriteBatchSize:           storeRegistry.Histograms("kv.store.write_batch_size_bytes", maxWriteBatchSize, 1),
+
+		rdbWriteStallCount:         storeRegistry.Counter("kv.store.write_stall_count"),
+		rdbWriteStallDurationNanos: storeRegistry.Latency("kv.store.write_stall_duration_nanos"),
 
 		// Range event metrics.
 		rangeSplits:                     storeRegistry.Counter("range.splits"),
@@ -695,12 +757,36 @@ func newStoreMetrics() *storeMetrics {
 		rangeSnapshotsGenerated:         storeRegistry.Counter("range.snapshots.generated"),
 		rangeSnapshotsNormalApplied:     storeRegistry.Counter("range.snapshots.normal-applied"),
 		rangeSnapshotsPreemptiveApplied: storeRegistry.Counter("range.snapshots.preemptive-applied"),
+		rangeSplitDurationNanos:         storeRegistry.Latency("kv.range.split_duration_nanos"),
+		rangeMergeDurationNanos:         storeRegistry.Latency("kv.range.merge_duration_nanos"),
+		rangeIDAllocations:              storeRegistry.Rates("kv.store.rangeid_alloc_per_second"),
+		rangeRebalances:                 storeRegistry.Rates("kv.rebalance.range_rebalances_per_second"),
+
+		// GC metrics.
+		gcBytesFreed: storeRegistry.Counter("storage.gc.bytes_freed"),
+		gcKeysFreed:  storeRegistry.Counter("storage.gc.keys_freed"),
+
+		// Intent resolver metrics.
+		txnPushCount:           storeRegistry.LabeledCounter("sql.txn.push_count", "type"),
+		intentResolveBatchSize: storeRegistry.Histograms("kv.intent.resolve_batch_size", 10000, 1),
 
 		// Raft processing metrics.
 		raftSelectDurationNanos:  storeRegistry.Counter("process-raft.waitingnanos"),
 		raftWorkingDurationNanos: storeRegistry.Counter("process-raft.workingnanos"),
 		raftTickingDurationNanos: storeRegistry.Counter("process-raft.tickingnanos"),
+		raftLogBehindCount:       storeRegistry.Gauge("raft.log.behind_count"),
+		raftLogEntrySize:         storeRegistry.Histograms("raft.log.entry_size_bytes", maxRaftLogEntrySize, 1),
+	}
+
+	for i := range sm.rdbCompactionBytesWritten {
+		sm.rdbCompactionBytesWritten[i] = storeRegistry.Gauge(
+			fmt.Sprintf("rocksdb.compaction.bytes-written.l%d", i))
+	}
+	for i := range sm.rdbCompactionBytesRead {
+		sm.rdbCompactionBytesRead[i] = storeRegistry.Gauge(
+			fmt.Sprintf("rocksdb.compaction.bytes-read.l%d", i))
 	}
+	return sm
 }
 
 // updateGaugesLocked breaks out individual metrics from the MVCCStats object.
@@ -732,13 +818,17 @@ func (sm *storeMetrics) updateCapacityGauges(capacity roachpb.StoreCapacity) {
 	sm.available.Update(capacity.Available)
 }
 
-func (sm *storeMetrics) updateReplicationGauges(leaders, replicated, pending, available int64) {
+func (sm *storeMetrics) updateReplicationGauges(
+	leaders, replicated, pending, available, behind, expiringSoon int64,
+) {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
 	sm.leaderRangeCount.Update(leaders)
 	sm.replicatedRangeCount.Update(replicated)
 	sm.replicationPendingRangeCount.Update(pending)
 	sm.availableRangeCount.Update(available)
+	sm.raftLogBehindCount.Update(behind)
+	sm.leaseExpiringSoonCount.Update(expiringSoon)
 }
 
 func (sm *storeMetrics) addMVCCStats(stats enginepb.MVCCStats) {
@@ -763,14 +853,46 @@ func (sm *storeMetrics) updateRocksDBStats(stats engine.Stats) {
 	sm.rdbBlockCacheMisses.Update(stats.BlockCacheMisses)
 	sm.rdbBlockCacheUsage.Update(stats.BlockCacheUsage)
 	sm.rdbBlockCachePinnedUsage.Update(stats.BlockCachePinnedUsage)
+	// Like the bloom filter hit rate below, this is the rate since startup.
+	if lookups := stats.BlockCacheHits + stats.BlockCacheMisses; lookups > 0 {
+		sm.rdbBlockCacheHitRate.Update(float64(stats.BlockCacheHits) / float64(lookups))
+	}
 	sm.rdbBloomFilterPrefixUseful.Update(stats.BloomFilterPrefixUseful)
 	sm.rdbBloomFilterPrefixChecked.Update(stats.BloomFilterPrefixChecked)
+	// A bloom filter check is a hit if it allowed a read to be skipped. The
+	// underlying tickers are cumulative, so this is the hit rate since startup.
+	if stats.BloomFilterPrefixChecked > 0 {
+		sm.rdbBloomFilterHitRate.Update(
+			float64(stats.BloomFilterPrefixUseful) / float64(stats.BloomFilterPrefixChecked))
+	}
 	sm.rdbMemtableHits.Update(stats.MemtableHits)
 	sm.rdbMemtableMisses.Update(stats.MemtableMisses)
 	sm.rdbMemtableTotalSize.Update(stats.MemtableTotalSize)
 	sm.rdbFlushes.Update(stats.Flushes)
 	sm.rdbCompactions.Update(stats.Compactions)
 	sm.rdbTableReadersMemEstimate.Update(stats.TableReadersMemEstimate)
+	sm.rdbWALBytesWritten.Update(stats.WALBytesWritten)
+	sm.rdbIteratorSeeks.Update(stats.IteratorSeeks)
+	sm.rdbIteratorNexts.Update(stats.IteratorNexts)
+	sm.rdbPendingCompactionBytes.Update(stats.PendingCompactionBytes)
+	for i, g := range sm.rdbCompactionBytesWritten {
+		g.Update(stats.CompactionBytesWritten[i])
+	}
+	for i, g := range sm.rdbCompactionBytesRead {
+		g.Update(stats.CompactionBytesRead[i])
+	}
+
+	// Any increase of the cumulative stall time since the last call is
+	// counted as a single stall.
+	sm.mu.Lock()
+	stalled := stats.WriteStallMicros - sm.lastWriteStallMicros
+	sm.lastWriteStallMicros = stats.WriteStallMicros
+	sm.mu.Unlock()
+	if stalled > 0 {
+		sm.rdbWriteStallCount.Inc(1)
+		sm.rdbWriteStallDurationNanos.RecordValue(
+			(time.Duration(stalled) * time.Microsecond).Nanoseconds())
+	}
 }
 
 func (sm *storeMetrics) leaseRequestComplete(success bool) {
@@ -815,6 +937,14 @@ func (sc *StoreContext) setDefaults() {
 	raftElectionTimeout := time.Duration(sc.RaftElectionTimeoutTicks) * sc.RaftTickInterval
 	sc.rangeLeaseActiveDuration = rangeLeaseRaftElectionTimeoutMultiplier * raftElectionTimeout
 	sc.rangeLeaseRenewalDuration = sc.rangeLeaseActiveDuration / rangeLeaseRenewalDivisor
+	sc.leaseExpiringSoonWindow = envutil.EnvOrDefaultDuration(
+		"lease_expiring_soon_window", sc.rangeLeaseRenewalDuration)
+}
+
+// writeBatchSizeRecorder is implemented by the RocksDB engines, which can
+// record the sizes of the batches committed to them.
+type writeBatchSizeRecorder interface {
+	SetWriteBatchSizes(metric.Histograms)
 }
 
 // NewStore returns a new instance of a store.
@@ -837,6 +967,9 @@ func NewStore(ctx StoreContext, eng engine.Engine, nodeDesc *roachpb.NodeDescrip
 	}
 	s.intentResolver = newIntentResolver(s)
 	s.drainLeases.Store(false)
+	if r, ok := eng.(writeBatchSizeRecorder); ok {
+		r.SetWriteBatchSizes(s.metrics.rdbWriteBatchSize)
+	}
 
 	s.mu.Lock()
 	s.mu.replicas = map[roachpb.RangeID]*Replica{}
@@ -1585,6 +1718,7 @@ func (s *Store) NewRangeDescriptor(
 	if err != nil {
 		return nil, err
 	}
+	s.metrics.rangeIDAllocations.Add(1)
 	desc := &roachpb.RangeDescriptor{
 		RangeID:       roachpb.RangeID(id),
 		StartKey:      start,
@@ -2590,7 +2724,6 @@ func (s *Store) processRaft() {
 				s.mu.Lock()
 				if r, ok := s.mu.replicas[st.Req.RangeID]; ok {
 					r.reportSnapshotStatus(st.Req.Message.To, st.Err)
-
 				}
 				s.mu.Unlock()
 				s.processRaftMu.Unlock()
@@ -2720,12 +2853,19 @@ func raftEntryFormatter(data []byte) string {
 }
 
 // computeReplicationStatus counts a number of simple replication statistics for
-// the ranges in this store.
+// the ranges in this store. raftLogBehindCount is the total number of committed
+// Raft log entries not yet acknowledged by the followers of ranges led by this
+// store. leaseExpiringSoonCount is the number of range leases held by this
+// store which stop covering new requests (i.e. enter their stasis period)
+// within the store's leaseExpiringSoonWindow. Since leases
+// are only renewed when the range is in use, this includes the leases of
+// idle ranges.
 // TODO(bram): #4564 It may be appropriate to compute these statistics while
 // scanning ranges. An ideal solution would be to create incremental events
 // whenever availability changes.
 func (s *Store) computeReplicationStatus(now int64) (
-	leaderRangeCount, replicatedRangeCount, replicationPendingRangeCount, availableRangeCount int64) {
+	leaderRangeCount, replicatedRangeCount, replicationPendingRangeCount, availableRangeCount,
+	raftLogBehindCount, leaseExpiringSoonCount int64) {
 	// Load the system config.
 	cfg, ok := s.Gossip().GetSystemConfig()
 	if !ok {
@@ -2734,6 +2874,7 @@ func (s *Store) computeReplicationStatus(now int64) (
 	}
 
 	timestamp := hlc.Timestamp{WallTime: now}
+	expiringSoon := timestamp.Add(s.ctx.leaseExpiringSoonWindow.Nanoseconds(), 0)
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	for _, rng := range s.mu.replicas {
@@ -2745,6 +2886,11 @@ func (s *Store) computeReplicationStatus(now int64) (
 		}
 		raftStatus := rng.RaftStatus()
 
+		if lease, _ := rng.getLease(); lease.OwnedBy(s.StoreID()) &&
+			lease.Covers(timestamp) && lease.StartStasis.Less(expiringSoon) {
+			leaseExpiringSoonCount++
+		}
+
 		if raftStatus != nil && raftStatus.SoftState.RaftState == raft.StateLeader {
 			leaderRangeCount++
 			// TODO(bram): #4564 Compare attributes of the stores so we can
@@ -2754,6 +2900,12 @@ func (s *Store) computeReplicationStatus(now int64) (
 				replicatedRangeCount++
 			}
 
+			for _, progress := range raftStatus.Progress {
+				if progress.Match < raftStatus.Commit {
+					raftLogBehindCount += int64(raftStatus.Commit - progress.Match)
+				}
+			}
+
 			// If any replica holds the range lease, the range is available.
 			if lease, _ := rng.getLease(); lease.Covers(timestamp) {
 				availableRangeCount++
@@ -2794,10 +2946,11 @@ func (s *Store) ComputeMetrics() error {
 
 	// broadcast replication status.
 	now := s.ctx.Clock.Now().WallTime
-	leaderRangeCount, replicatedRangeCount, replicationPendingRangeCount, availableRangeCount :=
-		s.computeReplicationStatus(now)
+	leaderRangeCount, replicatedRangeCount, replicationPendingRangeCount, availableRangeCount,
+		raftLogBehindCount, leaseExpiringSoonCount := s.computeReplicationStatus(now)
 	s.metrics.updateReplicationGauges(
-		leaderRangeCount, replicatedRangeCount, replicationPendingRangeCount, availableRangeCount)
+		leaderRangeCount, replicatedRangeCount, replicationPendingRangeCount, availableRangeCount,
+		raftLogBehindCount, leaseExpiringSoonCount)
 
 	// Get the latest RocksDB stats.
 	stats, err := s.engine.GetStats()
@@ -1292,6 +1292,9 @@ func TestStoreResolveWriteIntentRollback(t *testing.T) {
 		t.Fatal(pErr)
 	}
 
+	resolveBatchSize := store.metrics.intentResolveBatchSize[metric.Scale1M]
+	resolveBatches := resolveBatchSize.Current().TotalCount()
+
 	// Now, try a put using the pusher's txn.
 	h.Txn = pusher
 	args.Increment = 2
@@ -1300,6 +1303,15 @@ func TestStoreResolveWriteIntentRollback(t *testing.T) {
 	} else if reply := resp.(*roachpb.IncrementResponse); reply.NewValue != 2 {
 		t.Errorf("expected rollback of earlier increment to yield increment value of 2; got %d", reply.NewValue)
 	}
+
+	// The write pushed the pushee's transaction to abort it.
+	if a := store.metrics.txnPushCount.WithLabelValues("push_abort").Count(); a != 1 {
+		t.Errorf("expected 1 push_abort push, got %d", a)
+	}
+	// The pushee's intent was then resolved synchronously in a single batch.
+	if a, e := resolveBatchSize.Current().TotalCount(), resolveBatches+1; a != e {
+		t.Errorf("expected %d intent resolution batches, got %d", e, a)
+	}
 }
 
 // TestStoreResolveWriteIntentPushOnRead verifies that resolving a
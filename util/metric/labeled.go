@@ -0,0 +1,242 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	prometheusgo "github.com/prometheus/client_model/go"
+
+	"github.com/cockroachdb/cockroach/util/syncutil"
+)
+
+var _ Iterable = &LabeledCounter{}
+var _ Iterable = &LabeledGauge{}
+
+var _ json.Marshaler = &LabeledCounter{}
+var _ json.Marshaler = &LabeledGauge{}
+
+var _ PrometheusExportable = &LabeledCounter{}
+var _ PrometheusExportable = &LabeledGauge{}
+
+// labelSep separates label values when they are joined into a map key. It
+// cannot appear in valid UTF-8 label values.
+const labelSep = "\xff"
+
+// labelValues holds the label names of a labeled metric along with the label
+// values of every combination seen so far.
+type labelValues struct {
+	names []string
+
+	mu     syncutil.Mutex
+	values map[string][]string
+}
+
+func (lv *labelValues) init(names []string) {
+	if len(names) == 0 {
+		panic("labeled metric requires at least one label name")
+	}
+	lv.names = append([]string(nil), names...)
+	lv.values = map[string][]string{}
+}
+
+// key returns the map key for the given label values, recording them if they
+// have not been seen before. The caller must hold lv.mu.
+func (lv *labelValues) key(values []string) string {
+	if len(values) != len(lv.names) {
+		panic(fmt.Sprintf("expected %d label values for %v, got %d", len(lv.names), lv.names, len(values)))
+	}
+	k := strings.Join(values, labelSep)
+	if _, ok := lv.values[k]; !ok {
+		lv.values[k] = append([]string(nil), values...)
+	}
+	return k
+}
+
+// sortedKeys returns the keys of all recorded label combinations in sorted
+// order, so that exported metrics are stable across calls. The caller must
+// hold lv.mu.
+func (lv *labelValues) sortedKeys() []string {
+	keys := make([]string, 0, len(lv.values))
+	for k := range lv.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// labelPairs returns the prometheus label pairs for the given key.
+func (lv *labelValues) labelPairs(k string) []*prometheusgo.LabelPair {
+	values := lv.values[k]
+	pairs := make([]*prometheusgo.LabelPair, len(lv.names))
+	for i, name := range lv.names {
+		pairs[i] = &prometheusgo.LabelPair{
+			Name:  proto.String(name),
+			Value: proto.String(values[i]),
+		}
+	}
+	return pairs
+}
+
+// jsonKey returns a human-readable rendering of the label values for the
+// given key, e.g. "type=syntax,phase=parse".
+func (lv *labelValues) jsonKey(k string) string {
+	values := lv.values[k]
+	parts := make([]string, len(lv.names))
+	for i, name := range lv.names {
+		parts[i] = name + "=" + values[i]
+	}
+	return strings.Join(parts, ",")
+}
+
+// A LabeledCounter holds a Counter for every combination of values of its
+// label dimensions. It is exported to prometheus as a single metric family
+// with one labeled metric per combination.
+type LabeledCounter struct {
+	labelValues
+	counters map[string]*Counter
+}
+
+// NewLabeledCounter creates a LabeledCounter with the given label names.
+func NewLabeledCounter(labelNames ...string) *LabeledCounter {
+	lc := &LabeledCounter{counters: map[string]*Counter{}}
+	lc.init(labelNames)
+	return lc
+}
+
+// WithLabelValues returns the Counter for the given label values, creating it
+// if necessary. The values must be given in the order of the label names
+// passed to NewLabeledCounter.
+func (lc *LabeledCounter) WithLabelValues(values ...string) *Counter {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	k := lc.key(values)
+	c, ok := lc.counters[k]
+	if !ok {
+		c = NewCounter()
+		lc.counters[k] = c
+	}
+	return c
+}
+
+// EachLabeled calls the given closure with the label values and Counter of
+// every label combination, in sorted order.
+func (lc *LabeledCounter) EachLabeled(f func([]string, *Counter)) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	for _, k := range lc.sortedKeys() {
+		f(lc.values[k], lc.counters[k])
+	}
+}
+
+// Each calls the given closure with the empty string and itself.
+func (lc *LabeledCounter) Each(f func(string, interface{})) { f("", lc) }
+
+// MarshalJSON marshals to JSON.
+func (lc *LabeledCounter) MarshalJSON() ([]byte, error) {
+	m := make(map[string]int64)
+	lc.mu.Lock()
+	for k, c := range lc.counters {
+		m[lc.jsonKey(k)] = c.Count()
+	}
+	lc.mu.Unlock()
+	return json.Marshal(m)
+}
+
+// FillPrometheusMetric fills the appropriate metric fields.
+func (lc *LabeledCounter) FillPrometheusMetric(promMetric *prometheusgo.MetricFamily) {
+	promMetric.Type = prometheusgo.MetricType_COUNTER.Enum()
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	promMetric.Metric = make([]*prometheusgo.Metric, 0, len(lc.counters))
+	for _, k := range lc.sortedKeys() {
+		promMetric.Metric = append(promMetric.Metric, &prometheusgo.Metric{
+			Label:   lc.labelPairs(k),
+			Counter: &prometheusgo.Counter{Value: proto.Float64(float64(lc.counters[k].Count()))},
+		})
+	}
+}
+
+// A LabeledGauge holds a Gauge for every combination of values of its label
+// dimensions. It is exported to prometheus as a single metric family with one
+// labeled metric per combination.
+type LabeledGauge struct {
+	labelValues
+	gauges map[string]*Gauge
+}
+
+// NewLabeledGauge creates a LabeledGauge with the given label names.
+func NewLabeledGauge(labelNames ...string) *LabeledGauge {
+	lg := &LabeledGauge{gauges: map[string]*Gauge{}}
+	lg.init(labelNames)
+	return lg
+}
+
+// WithLabelValues returns the Gauge for the given label values, creating it if
+// necessary. The values must be given in the order of the label names passed
+// to NewLabeledGauge.
+func (lg *LabeledGauge) WithLabelValues(values ...string) *Gauge {
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+	k := lg.key(values)
+	g, ok := lg.gauges[k]
+	if !ok {
+		g = NewGauge()
+		lg.gauges[k] = g
+	}
+	return g
+}
+
+// EachLabeled calls the given closure with the label values and Gauge of
+// every label combination, in sorted order.
+func (lg *LabeledGauge) EachLabeled(f func([]string, *Gauge)) {
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+	for _, k := range lg.sortedKeys() {
+		f(lg.values[k], lg.gauges[k])
+	}
+}
+
+// Each calls the given closure with the empty string and itself.
+func (lg *LabeledGauge) Each(f func(string, interface{})) { f("", lg) }
+
+// MarshalJSON marshals to JSON.
+func (lg *LabeledGauge) MarshalJSON() ([]byte, error) {
+	m := make(map[string]int64)
+	lg.mu.Lock()
+	for k, g := range lg.gauges {
+		m[lg.jsonKey(k)] = g.Value()
+	}
+	lg.mu.Unlock()
+	return json.Marshal(m)
+}
+
+// FillPrometheusMetric fills the appropriate metric fields.
+func (lg *LabeledGauge) FillPrometheusMetric(promMetric *prometheusgo.MetricFamily) {
+	promMetric.Type = prometheusgo.MetricType_GAUGE.Enum()
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+	promMetric.Metric = make([]*prometheusgo.Metric, 0, len(lg.gauges))
+	for _, k := range lg.sortedKeys() {
+		promMetric.Metric = append(promMetric.Metric, &prometheusgo.Metric{
+			Label: lg.labelPairs(k),
+			Gauge: &prometheusgo.Gauge{Value: proto.Float64(float64(lg.gauges[k].Value()))},
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLabeledCounter(t *testing.T) {
+	c := NewLabeledCounter("type")
+	c.WithLabelValues("syntax").Inc(2)
+	c.WithLabelValues("internal").Inc(1)
+	c.WithLabelValues("syntax").Inc(3)
+	if v := c.WithLabelValues("syntax").Count(); v != 5 {
+		t.Fatalf("unexpected value: %d", v)
+	}
+	testMarshal(t, c, `{"type=internal":1,"type=syntax":5}`)
+}
+
+func TestLabeledGauge(t *testing.T) {
+	g := NewLabeledGauge("store", "level")
+	g.WithLabelValues("1", "0").Update(10)
+	g.WithLabelValues("1", "1").Update(20)
+	if v := g.WithLabelValues("1", "0").Value(); v != 10 {
+		t.Fatalf("unexpected value: %d", v)
+	}
+	testMarshal(t, g, `{"store=1,level=0":10,"store=1,level=1":20}`)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected panic on wrong number of label values")
+		}
+	}()
+	g.WithLabelValues("1")
+}
+
+func TestLabeledPrintAsText(t *testing.T) {
+	r := NewRegistry()
+	c := r.LabeledCounter("sql.errors", "type")
+	c.WithLabelValues("syntax").Inc(2)
+	c.WithLabelValues("internal").Inc(1)
+	g := r.LabeledGauge("queue.depth", "queue")
+	g.WithLabelValues("gc").Update(3)
+
+	var buf bytes.Buffer
+	if err := r.PrintAsText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, exp := range []string{
+		"# TYPE sql_errors counter\n" +
+			"sql_errors{type=\"internal\"} 1\n" +
+			"sql_errors{type=\"syntax\"} 2\n",
+		"# TYPE queue_depth gauge\n" +
+			"queue_depth{queue=\"gc\"} 3\n",
+	} {
+		if !bytes.Contains(buf.Bytes(), []byte(exp)) {
+			t.Errorf("expected output to contain:\n%s\ngot:\n%s", exp, buf.String())
+		}
+	}
+}
@@ -19,6 +19,7 @@ package metric
 import (
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/VividCortex/ewma"
@@ -39,11 +40,26 @@ type TimeScale struct {
 	d    time.Duration
 }
 
+// NewTimeScale creates a TimeScale with the given name and duration. The name
+// is used as the suffix of the metrics generated for the TimeScale. A
+// non-positive duration is illegal and will cause a panic.
+func NewTimeScale(name string, d time.Duration) TimeScale {
+	if d <= 0 {
+		panic(fmt.Sprintf("time scale %q has non-positive duration %s", name, d))
+	}
+	return TimeScale{name: name, d: d}
+}
+
 // Name returns the name of the TimeScale.
 func (ts TimeScale) Name() string {
 	return ts.name
 }
 
+// Duration returns the duration of the TimeScale.
+func (ts TimeScale) Duration() time.Duration {
+	return ts.d
+}
+
 var (
 	// Scale1M is a 1 minute window for windowed stats (e.g. Rates and Histograms).
 	Scale1M = TimeScale{"1m", 1 * time.Minute}
@@ -80,6 +96,7 @@ var _ json.Marshaler = &Counter{}
 var _ json.Marshaler = &Histogram{}
 var _ json.Marshaler = &Rate{}
 var _ json.Marshaler = &Registry{}
+var _ json.Unmarshaler = &Registry{}
 
 var _ PrometheusExportable = &Gauge{}
 var _ PrometheusExportable = &GaugeFloat64{}
@@ -125,7 +142,12 @@ type Histogram struct {
 // NewHistogram creates a new windowed HDRHistogram with the given parameters.
 // Data is kept in the active window for approximately the given duration.
 // See the documentation for hdrhistogram.WindowedHistogram for details.
+// Durations too short to be split across the windows of the histogram (in
+// particular, non-positive ones) are illegal and will cause a panic.
 func NewHistogram(duration time.Duration, maxVal int64, sigFigs int) *Histogram {
+	if duration/histWrapNum <= 0 {
+		panic(fmt.Sprintf("histogram duration %s is too short for %d windows", duration, histWrapNum))
+	}
 	h := &Histogram{}
 	h.maxVal = maxVal
 	h.nextT = now()
@@ -174,6 +196,15 @@ func (h *Histogram) Current() *hdrhistogram.Histogram {
 	return hdrhistogram.Import(export)
 }
 
+// Percentile returns the value at the given percentile, between 0 and 100, of
+// the data currently in the window. It returns zero if the window is empty.
+func (h *Histogram) Percentile(p float64) float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	maybeTick(h)
+	return float64(h.windowed.Merge().ValueAtQuantile(p))
+}
+
 // Each calls the closure with the empty string and the receiver.
 func (h *Histogram) Each(f func(string, interface{})) {
 	h.mu.Lock()
@@ -228,6 +259,19 @@ func NewCounter() *Counter {
 // Each calls the given closure with the empty string and itself.
 func (c *Counter) Each(f func(string, interface{})) { f("", c) }
 
+// Reset atomically sets the Counter to zero.
+func (c *Counter) Reset() { c.Counter.Clear() }
+
+// Add atomically increments the Counter by the given delta, which must not be
+// negative. Unlike Inc, it is meant for counters which only ever increase, and
+// it panics if misused to decrease one.
+func (c *Counter) Add(delta int64) {
+	if delta < 0 {
+		panic(fmt.Sprintf("counter cannot be decreased by adding %d", delta))
+	}
+	c.Counter.Inc(delta)
+}
+
 // MarshalJSON marshals to JSON.
 func (c *Counter) MarshalJSON() ([]byte, error) {
 	return json.Marshal(c.Counter.Count())
@@ -243,28 +287,50 @@ func (c *Counter) FillPrometheusMetric(promMetric *prometheusgo.MetricFamily) {
 
 // A Gauge atomically stores a single integer value.
 type Gauge struct {
-	metrics.Gauge
+	value int64
 }
 
 // NewGauge creates a Gauge.
 func NewGauge() *Gauge {
-	g := &Gauge{metrics.NewGauge()}
-	return g
+	return &Gauge{}
+}
+
+// Update atomically sets the Gauge to the given value.
+func (g *Gauge) Update(v int64) { atomic.StoreInt64(&g.value, v) }
+
+// Value atomically returns the value of the Gauge.
+func (g *Gauge) Value() int64 { return atomic.LoadInt64(&g.value) }
+
+// Inc atomically increments the Gauge by the given delta, which may be
+// negative.
+func (g *Gauge) Inc(delta int64) { atomic.AddInt64(&g.value, delta) }
+
+// Dec atomically decrements the Gauge by the given delta.
+func (g *Gauge) Dec(delta int64) { atomic.AddInt64(&g.value, -delta) }
+
+// CompareAndSwap atomically sets the Gauge to new if it currently holds old,
+// and reports whether it did. This allows callers to implement conditional
+// updates such as max-tracking without external locking.
+func (g *Gauge) CompareAndSwap(old, new int64) bool {
+	return atomic.CompareAndSwapInt64(&g.value, old, new)
 }
 
 // Each calls the given closure with the empty string and itself.
 func (g *Gauge) Each(f func(string, interface{})) { f("", g) }
 
+// Reset atomically sets the Gauge to zero.
+func (g *Gauge) Reset() { g.Update(0) }
+
 // MarshalJSON marshals to JSON.
 func (g *Gauge) MarshalJSON() ([]byte, error) {
-	return json.Marshal(g.Gauge.Value())
+	return json.Marshal(g.Value())
 }
 
 // FillPrometheusMetric fills the appropriate metric fields.
 func (g *Gauge) FillPrometheusMetric(promMetric *prometheusgo.MetricFamily) {
 	promMetric.Type = prometheusgo.MetricType_GAUGE.Enum()
 	promMetric.Metric = []*prometheusgo.Metric{
-		{Gauge: &prometheusgo.Gauge{Value: proto.Float64(float64(g.Gauge.Value()))}},
+		{Gauge: &prometheusgo.Gauge{Value: proto.Float64(float64(g.Value()))}},
 	}
 }
 
@@ -282,6 +348,9 @@ func NewGaugeFloat64() *GaugeFloat64 {
 // Each calls the given closure with the empty string and itself.
 func (g *GaugeFloat64) Each(f func(string, interface{})) { f("", g) }
 
+// Reset atomically sets the GaugeFloat64 to zero.
+func (g *GaugeFloat64) Reset() { g.GaugeFloat64.Update(0) }
+
 // MarshalJSON marshals to JSON.
 func (g *GaugeFloat64) MarshalJSON() ([]byte, error) {
 	return json.Marshal(g.GaugeFloat64.Value())
@@ -346,6 +415,16 @@ func (e *Rate) Add(v float64) {
 	e.mu.Unlock()
 }
 
+// Reset discards all measurements added to the Rate so far, setting its value
+// back to zero.
+func (e *Rate) Reset() {
+	e.mu.Lock()
+	e.curSum = 0
+	e.wrapped.Set(0)
+	e.nextT = now()
+	e.mu.Unlock()
+}
+
 // Each calls the given closure with the empty string and the Rate's current
 // value. TODO(mrtracy): Fix this to pass the Rate object itself to 'f', to
 // match the 'visitor' behavior as the other metric types (currently, it passes
@@ -19,9 +19,12 @@ package metric
 import (
 	"bytes"
 	"encoding/json"
+	"sync"
 	"testing"
 	"time"
 
+	prometheusgo "github.com/prometheus/client_model/go"
+
 	_ "github.com/cockroachdb/cockroach/util/log" // for flags
 )
 
@@ -40,6 +43,63 @@ func TestGauge(t *testing.T) {
 	testMarshal(t, g, "10")
 }
 
+func TestGaugeIncDec(t *testing.T) {
+	g := NewGauge()
+	g.Inc(5)
+	g.Dec(7)
+	if v := g.Value(); v != -2 {
+		t.Fatalf("unexpected value: %d", v)
+	}
+}
+
+func TestGaugeConcurrentInc(t *testing.T) {
+	g := NewGauge()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				g.Inc(2)
+				g.Dec(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if v := g.Value(); v != 1000 {
+		t.Fatalf("unexpected value: %d", v)
+	}
+}
+
+func TestGaugeCompareAndSwap(t *testing.T) {
+	g := NewGauge()
+	g.Update(10)
+	if g.CompareAndSwap(5, 20) {
+		t.Error("unexpected swap of mismatched value")
+	}
+	if !g.CompareAndSwap(10, 20) {
+		t.Error("expected swap to succeed")
+	}
+	if v := g.Value(); v != 20 {
+		t.Fatalf("unexpected value: %d", v)
+	}
+}
+
+func TestGaugeReset(t *testing.T) {
+	g := NewGauge()
+	g.Update(10)
+	g.Reset()
+	if v := g.Value(); v != 0 {
+		t.Fatalf("unexpected value after reset: %d", v)
+	}
+	gf := NewGaugeFloat64()
+	gf.Update(10.4)
+	gf.Reset()
+	if v := gf.Value(); v != 0 {
+		t.Fatalf("unexpected value after reset: %f", v)
+	}
+}
+
 func TestGaugeFloat64(t *testing.T) {
 	g := NewGaugeFloat64()
 	g.Update(10.4)
@@ -47,6 +107,12 @@ func TestGaugeFloat64(t *testing.T) {
 		t.Fatalf("unexpected value: %f", v)
 	}
 	testMarshal(t, g, "10.4")
+
+	var mf prometheusgo.MetricFamily
+	g.FillPrometheusMetric(&mf)
+	if v := mf.Metric[0].GetGauge().GetValue(); v != 10.4 {
+		t.Fatalf("unexpected prometheus value: %f", v)
+	}
 }
 
 func TestCounter(t *testing.T) {
@@ -60,6 +126,39 @@ func TestCounter(t *testing.T) {
 	testMarshal(t, c, "90")
 }
 
+func TestCounterAdd(t *testing.T) {
+	c := NewCounter()
+	c.Add(7)
+	c.Add(0)
+	c.Add(3)
+	if v := c.Count(); v != 10 {
+		t.Fatalf("unexpected value: %d", v)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected panic adding a negative delta")
+		}
+		if v := c.Count(); v != 10 {
+			t.Fatalf("unexpected value after failed add: %d", v)
+		}
+	}()
+	c.Add(-1)
+}
+
+func TestCounterReset(t *testing.T) {
+	c := NewCounter()
+	c.Inc(100)
+	c.Reset()
+	if v := c.Count(); v != 0 {
+		t.Fatalf("unexpected value after reset: %d", v)
+	}
+	c.Inc(5)
+	if v := c.Count(); v != 5 {
+		t.Fatalf("unexpected value: %d", v)
+	}
+}
+
 func setNow(d time.Duration) {
 	now = func() time.Time {
 		return time.Time{}.Add(d)
@@ -98,12 +197,41 @@ func TestHistogramRotate(t *testing.T) {
 func TestHistogramJSON(t *testing.T) {
 	defer TestingSetNow(nil)()
 	setNow(0)
-	h := NewHistogram(0, 1, 3)
+	h := NewHistogram(time.Minute, 1, 3)
 	testMarshal(t, h, `[{"Quantile":100,"Count":0,"ValueAt":0}]`)
 	h.RecordValue(1)
 	testMarshal(t, h, `[{"Quantile":0,"Count":1,"ValueAt":1},{"Quantile":100,"Count":1,"ValueAt":1}]`)
 }
 
+func TestHistogramPercentile(t *testing.T) {
+	defer TestingSetNow(nil)()
+	setNow(0)
+	h := NewHistogram(time.Minute, 1000, 3)
+	if p := h.Percentile(99); p != 0 {
+		t.Errorf("expected 0 for empty histogram, got %v", p)
+	}
+	for i := int64(1); i <= 100; i++ {
+		h.RecordValue(i)
+	}
+	for _, tc := range []struct {
+		p, exp float64
+	}{
+		{1, 1},
+		{50, 50},
+		{99, 99},
+		{100, 100},
+	} {
+		if v := h.Percentile(tc.p); v != tc.exp {
+			t.Errorf("%v: expected %v, got %v", tc.p, tc.exp, v)
+		}
+	}
+	// Data rotates out of the window like for all other accessors.
+	setNow(2 * time.Minute)
+	if p := h.Percentile(99); p != 0 {
+		t.Errorf("expected 0 after rotation, got %v", p)
+	}
+}
+
 func TestRateRotate(t *testing.T) {
 	defer TestingSetNow(nil)()
 	setNow(0)
@@ -137,3 +265,46 @@ func TestRateRotate(t *testing.T) {
 	expBytes, _ := json.Marshal(v)
 	testMarshal(t, r, string(expBytes))
 }
+
+func TestRateReset(t *testing.T) {
+	defer TestingSetNow(nil)()
+	setNow(0)
+	r := NewRate(10 * time.Second)
+	// Skip the warmup phase of the wrapped EWMA for this test.
+	for i := 0; i < 100; i++ {
+		r.wrapped.Add(0)
+	}
+	r.Add(100)
+	setNow(time.Second)
+	if v := r.Value(); v == 0 {
+		t.Fatalf("expected nonzero value before reset")
+	}
+	r.Add(100)
+	r.Reset()
+	setNow(3 * time.Second)
+	if v := r.Value(); v != 0 {
+		t.Fatalf("unexpected value after reset: %v", v)
+	}
+}
+
+func TestInvalidDurations(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fn   func()
+	}{
+		{"zero time scale", func() { _ = NewTimeScale("0s", 0) }},
+		{"negative time scale", func() { _ = NewTimeScale("neg", -time.Second) }},
+		{"zero histogram", func() { _ = NewHistogram(0, 100, 1) }},
+		{"negative histogram", func() { _ = NewHistogram(-time.Minute, 100, 1) }},
+		{"zero rate", func() { _ = NewRate(0) }},
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("%s: expected panic", tc.name)
+				}
+			}()
+			tc.fn()
+		}()
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	prometheusgo "github.com/prometheus/client_model/go"
+)
+
+// OpenMetricsContentType is the HTTP content type of the output of
+// WriteOpenMetrics.
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// WriteOpenMetrics outputs all metrics in the OpenMetrics text format,
+// terminated by the mandatory "# EOF" marker. The vendored prometheus
+// libraries predate OpenMetrics, so the encoding is done here based on the
+// metric families filled in by PrometheusExportable.
+func (r *Registry) WriteOpenMetrics(w io.Writer) error {
+	var metricFamily prometheusgo.MetricFamily
+	var buf bytes.Buffer
+	labels, _ := r.labelPairs()
+	var ret error
+	r.eachQualified(func(name, help string, v interface{}) {
+		if ret != nil {
+			return
+		}
+		if metric, ok := v.(PrometheusExportable); ok {
+			metricFamily.Reset()
+			metricFamily.Name = proto.String(exportedName(name))
+			if help != "" {
+				metricFamily.Help = proto.String(help)
+			}
+			metric.FillPrometheusMetric(&metricFamily)
+			if len(metricFamily.Metric) == 0 {
+				// Labeled metrics without any label values have no samples.
+				return
+			}
+			addLabels(&metricFamily, labels)
+			buf.Reset()
+			writeOpenMetricsFamily(&buf, &metricFamily)
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				ret = err
+			}
+		}
+	})
+	if ret != nil {
+		return ret
+	}
+	_, err := io.WriteString(w, "# EOF\n")
+	return err
+}
+
+// writeOpenMetricsFamily writes the TYPE line and the samples of the given
+// metric family to buf.
+func writeOpenMetricsFamily(buf *bytes.Buffer, mf *prometheusgo.MetricFamily) {
+	name := mf.GetName()
+	var typ string
+	switch mf.GetType() {
+	case prometheusgo.MetricType_COUNTER:
+		typ = "counter"
+		// The _total suffix belongs to the counter samples, not to the
+		// family. Counters whose name already ends in it (e.g.
+		// sql.rows_read_total) must not be exported as *_total_total.
+		name = strings.TrimSuffix(name, "_total")
+	case prometheusgo.MetricType_GAUGE:
+		typ = "gauge"
+	case prometheusgo.MetricType_SUMMARY:
+		typ = "summary"
+	default:
+		typ = "unknown"
+	}
+	fmt.Fprintf(buf, "# TYPE %s %s\n", name, typ)
+	if mf.Help != nil {
+		fmt.Fprintf(buf, "# HELP %s %s\n", name, escapeOpenMetricsLabel(mf.GetHelp()))
+	}
+
+	for _, m := range mf.Metric {
+		switch mf.GetType() {
+		case prometheusgo.MetricType_COUNTER:
+			// OpenMetrics requires counter samples to carry the _total suffix.
+			writeOpenMetricsSample(buf, name+"_total", m.Label, "", 0, m.GetCounter().GetValue())
+		case prometheusgo.MetricType_GAUGE:
+			writeOpenMetricsSample(buf, name, m.Label, "", 0, m.GetGauge().GetValue())
+		case prometheusgo.MetricType_SUMMARY:
+			s := m.GetSummary()
+			for _, q := range s.Quantile {
+				// Our quantiles are percentiles, but OpenMetrics requires
+				// them to be between 0 and 1.
+				writeOpenMetricsSample(buf, name, m.Label, "quantile", q.GetQuantile()/100, q.GetValue())
+			}
+			if s.SampleSum != nil {
+				writeOpenMetricsSample(buf, name+"_sum", m.Label, "", 0, s.GetSampleSum())
+			}
+			writeOpenMetricsSample(buf, name+"_count", m.Label, "", 0, float64(s.GetSampleCount()))
+		default:
+			writeOpenMetricsSample(buf, name, m.Label, "", 0, m.GetUntyped().GetValue())
+		}
+	}
+}
+
+// writeOpenMetricsSample writes a single sample line. If extraName is
+// non-empty, an additional label with that name and the value extraValue is
+// appended to the given labels.
+func writeOpenMetricsSample(
+	buf *bytes.Buffer,
+	name string,
+	labels []*prometheusgo.LabelPair,
+	extraName string,
+	extraValue float64,
+	value float64,
+) {
+	buf.WriteString(name)
+	if len(labels) > 0 || extraName != "" {
+		buf.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			fmt.Fprintf(buf, "%s=\"%s\"", l.GetName(), escapeOpenMetricsLabel(l.GetValue()))
+		}
+		if extraName != "" {
+			if len(labels) > 0 {
+				buf.WriteByte(',')
+			}
+			fmt.Fprintf(buf, "%s=\"%s\"", extraName, formatOpenMetricsFloat(extraValue))
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(formatOpenMetricsFloat(value))
+	buf.WriteByte('\n')
+}
+
+var openMetricsLabelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeOpenMetricsLabel(v string) string {
+	return openMetricsLabelEscaper.Replace(v)
+}
+
+func formatOpenMetricsFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,70 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistryWriteOpenMetrics(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("some.counter").Inc(3)
+	r.Counter("rows_read_total").Inc(4)
+	r.Gauge("some-gauge").Update(-2)
+	r.GaugeFloat64("float.gauge").Update(0.25)
+	r.LabeledCounter("errors", "type").WithLabelValues(`a "quoted"\value`).Inc(1)
+	// Labeled counters without label values have no samples and are skipped.
+	_ = r.LabeledCounter("unused", "type")
+	r.Histogram("histo", time.Minute, 1000, 1).RecordValue(10)
+	// Rates are not PrometheusExportable and must be skipped.
+	_ = r.Rate("rate", time.Minute)
+
+	var buf bytes.Buffer
+	if err := r.WriteOpenMetrics(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, exp := range []string{
+		"# TYPE some_counter counter\nsome_counter_total 3\n",
+		"# TYPE rows_read counter\nrows_read_total 4\n",
+		"# TYPE some_gauge gauge\nsome_gauge -2\n",
+		"# TYPE float_gauge gauge\nfloat_gauge 0.25\n",
+		"# TYPE errors counter\nerrors_total{type=\"a \\\"quoted\\\"\\\\value\"} 1\n",
+		"# TYPE histo summary\n",
+		"histo{quantile=\"0\"} 10\n",
+		"histo{quantile=\"1\"} 10\n",
+		"histo_count 1\n",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected output to contain %q, got:\n%s", exp, out)
+		}
+	}
+	if strings.Contains(out, "_total_total") {
+		t.Errorf("unexpected doubled _total suffix in output:\n%s", out)
+	}
+	if strings.Contains(out, "rate") {
+		t.Errorf("unexpected rate in output:\n%s", out)
+	}
+	if strings.Contains(out, "unused") {
+		t.Errorf("unexpected empty family in output:\n%s", out)
+	}
+	if !strings.HasSuffix(out, "\n# EOF\n") {
+		t.Errorf("expected output to end with EOF marker, got:\n%s", out)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	prometheusgo "github.com/prometheus/client_model/go"
+
+	"github.com/cockroachdb/cockroach/util/syncutil"
+)
+
+var _ Iterable = &GaugeWithPeaks{}
+var _ json.Marshaler = &GaugeWithPeaks{}
+var _ PrometheusExportable = &GaugeWithPeaks{}
+var _ periodic = &GaugeWithPeaks{}
+
+// peakLabel is the name of the label which tells apart the current value and
+// the peaks of a GaugeWithPeaks when exported to prometheus.
+const peakLabel = "peak"
+
+// peakWrapNum is the number of windows the peaks of a GaugeWithPeaks are
+// tracked in.
+const peakWrapNum = 2
+
+// A GaugeWithPeaks is a Gauge which additionally tracks the highest and lowest
+// values it held during approximately the last duration it was created with.
+// This makes short-lived spikes, e.g. of queue depths, visible to scrapers
+// which only sample the current value. Reading or exporting the peaks does not
+// reset them, so any number of consumers can sample the gauge independently.
+type GaugeWithPeaks struct {
+	mu  syncutil.Mutex
+	val int64
+	// windows holds the peaks of the current window, followed by those of the
+	// previous ones.
+	windows  [peakWrapNum]peaks
+	nextT    time.Time
+	duration time.Duration
+}
+
+type peaks struct {
+	max, min int64
+}
+
+// NewGaugeWithPeaks creates a GaugeWithPeaks whose peaks cover approximately
+// the given duration. Durations too short to be split across the windows of
+// the gauge (in particular, non-positive ones) are illegal and will cause a
+// panic.
+func NewGaugeWithPeaks(duration time.Duration) *GaugeWithPeaks {
+	if duration/peakWrapNum <= 0 {
+		panic(fmt.Sprintf("peak duration %s is too short for %d windows", duration, peakWrapNum))
+	}
+	return &GaugeWithPeaks{
+		nextT:    now(),
+		duration: duration,
+	}
+}
+
+func (g *GaugeWithPeaks) tick() {
+	g.nextT = g.nextT.Add(g.duration / peakWrapNum)
+	copy(g.windows[1:], g.windows[:peakWrapNum-1])
+	g.windows[0] = peaks{max: g.val, min: g.val}
+}
+
+func (g *GaugeWithPeaks) nextTick() time.Time {
+	return g.nextT
+}
+
+// Update sets the current value of the gauge.
+func (g *GaugeWithPeaks) Update(v int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.setLocked(v)
+}
+
+// Inc increments the current value of the gauge by the given delta, which may
+// be negative.
+func (g *GaugeWithPeaks) Inc(delta int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.setLocked(g.val + delta)
+}
+
+// Dec decrements the current value of the gauge by the given delta.
+func (g *GaugeWithPeaks) Dec(delta int64) {
+	g.Inc(-delta)
+}
+
+func (g *GaugeWithPeaks) setLocked(v int64) {
+	maybeTick(g)
+	g.val = v
+	if w := &g.windows[0]; v > w.max {
+		w.max = v
+	} else if v < w.min {
+		w.min = v
+	}
+}
+
+// peaksLocked returns the peaks across all windows.
+func (g *GaugeWithPeaks) peaksLocked() peaks {
+	maybeTick(g)
+	p := g.windows[0]
+	for _, w := range g.windows[1:] {
+		if w.max > p.max {
+			p.max = w.max
+		}
+		if w.min < p.min {
+			p.min = w.min
+		}
+	}
+	return p
+}
+
+// Value returns the current value of the gauge.
+func (g *GaugeWithPeaks) Value() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.val
+}
+
+// Max returns the highest value of the gauge during the tracked duration.
+func (g *GaugeWithPeaks) Max() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.peaksLocked().max
+}
+
+// Min returns the lowest value of the gauge during the tracked duration.
+func (g *GaugeWithPeaks) Min() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.peaksLocked().min
+}
+
+// ResetPeaks sets both peaks to the current value of the gauge.
+func (g *GaugeWithPeaks) ResetPeaks() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i := range g.windows {
+		g.windows[i] = peaks{max: g.val, min: g.val}
+	}
+}
+
+// Each calls the given closure with the empty string and itself.
+func (g *GaugeWithPeaks) Each(f func(string, interface{})) { f("", g) }
+
+// MarshalJSON marshals to JSON.
+func (g *GaugeWithPeaks) MarshalJSON() ([]byte, error) {
+	g.mu.Lock()
+	p := g.peaksLocked()
+	m := map[string]int64{"value": g.val, "max": p.max, "min": p.min}
+	g.mu.Unlock()
+	return json.Marshal(m)
+}
+
+// FillPrometheusMetric fills the appropriate metric fields: one unlabeled
+// gauge with the current value, and one gauge each for the peaks, labeled
+// peak="max" and peak="min".
+func (g *GaugeWithPeaks) FillPrometheusMetric(promMetric *prometheusgo.MetricFamily) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	promMetric.Type = prometheusgo.MetricType_GAUGE.Enum()
+	peak := func(name string, v int64) *prometheusgo.Metric {
+		return &prometheusgo.Metric{
+			Label: []*prometheusgo.LabelPair{{
+				Name:  proto.String(peakLabel),
+				Value: proto.String(name),
+			}},
+			Gauge: &prometheusgo.Gauge{Value: proto.Float64(float64(v))},
+		}
+	}
+	p := g.peaksLocked()
+	promMetric.Metric = []*prometheusgo.Metric{
+		{Gauge: &prometheusgo.Gauge{Value: proto.Float64(float64(g.val))}},
+		peak("max", p.max),
+		peak("min", p.min),
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package metric
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	prometheusgo "github.com/prometheus/client_model/go"
+)
+
+func TestGaugeWithPeaks(t *testing.T) {
+	defer TestingSetNow(nil)()
+	setNow(0)
+	g := NewGaugeWithPeaks(peakWrapNum * time.Second)
+	g.Update(5)
+	g.Inc(10)
+	g.Dec(20)
+	g.Update(3)
+	if v, max, min := g.Value(), g.Max(), g.Min(); v != 3 || max != 15 || min != -5 {
+		t.Fatalf("unexpected value %d, max %d, min %d", v, max, min)
+	}
+	testMarshal(t, g, `{"max":15,"min":-5,"value":3}`)
+
+	// Exporting to prometheus doesn't reset the peaks, so every consumer sees
+	// the same values.
+	for i := 0; i < 2; i++ {
+		var mf prometheusgo.MetricFamily
+		g.FillPrometheusMetric(&mf)
+		if len(mf.Metric) != 3 {
+			t.Fatalf("expected 3 metrics, got %v", mf.Metric)
+		}
+		for j, exp := range []float64{3, 15, -5} {
+			if v := mf.Metric[j].GetGauge().GetValue(); v != exp {
+				t.Errorf("%d: %d: expected %v, got %v", i, j, exp, v)
+			}
+		}
+	}
+
+	// The peaks are still covered after one window has passed, ...
+	setNow(time.Second)
+	g.Update(4)
+	if max, min := g.Max(), g.Min(); max != 15 || min != -5 {
+		t.Errorf("unexpected max %d, min %d", max, min)
+	}
+	// ... but have moved out once all windows have rotated.
+	setNow(peakWrapNum * time.Second)
+	if max, min := g.Max(), g.Min(); max != 4 || min != 3 {
+		t.Errorf("unexpected max %d, min %d", max, min)
+	}
+	setNow(2 * peakWrapNum * time.Second)
+	if max, min := g.Max(), g.Min(); max != 4 || min != 4 {
+		t.Errorf("unexpected max %d, min %d", max, min)
+	}
+}
+
+func TestGaugeWithPeaksText(t *testing.T) {
+	r := NewRegistry()
+	r.GaugeWithPeaks("queue", time.Minute).Update(7)
+	var buf bytes.Buffer
+	if err := r.PrintAsText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, exp := range []string{"queue 7\n", `queue{peak="max"} 7`, `queue{peak="min"} 0`} {
+		if !strings.Contains(buf.String(), exp) {
+			t.Errorf("expected output to contain %q, got:\n%s", exp, buf.String())
+		}
+	}
+}
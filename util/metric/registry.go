@@ -21,7 +21,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"reflect"
 	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/cockroach/util/syncutil"
@@ -44,12 +47,25 @@ var DefaultTimeScales = []TimeScale{Scale1M, Scale10M, Scale1H}
 type Registry struct {
 	syncutil.Mutex
 	tracked map[string]Iterable
+	// help maps format strings to the documentation given to AddWithHelp.
+	help map[string]string
+	// prefix is prepended to every metric name when the registry is
+	// serialized directly (see SetPrefix).
+	prefix string
+	// labels are attached to every metric when the registry is serialized
+	// directly (see SetLabel), in the order in which they were first set.
+	labels []registryLabel
+}
+
+type registryLabel struct {
+	name, value string
 }
 
 // NewRegistry creates a new Registry.
 func NewRegistry() *Registry {
 	return &Registry{
 		tracked: map[string]Iterable{},
+		help:    map[string]string{},
 	}
 }
 
@@ -61,12 +77,53 @@ func NewRegistry() *Registry {
 // and registered in a single step. Add is called manually only when adding
 // a registry to another, or when integrating metrics defined elsewhere.
 func (r *Registry) Add(format string, item Iterable) error {
+	return r.AddWithHelp(format, "", item)
+}
+
+// AddWithHelp is like Add, but also attaches the given documentation to the
+// item. It is exported as the HELP text of the metric, or, if the item is a
+// Registry, of every metric in it which does not have its own.
+func (r *Registry) AddWithHelp(format, help string, item Iterable) error {
 	r.Lock()
 	defer r.Unlock()
 	if _, ok := r.tracked[format]; ok {
 		return errors.New("format string already in use")
 	}
 	r.tracked[format] = item
+	if help != "" {
+		r.help[format] = help
+	}
+	return nil
+}
+
+// Merge adds all items tracked by other, along with their help text, to r.
+// If any of their format strings is already in use in r, an error is
+// returned and r is left unchanged. The items are shared, not copied.
+func (r *Registry) Merge(other *Registry) error {
+	other.Lock()
+	tracked := make(map[string]Iterable, len(other.tracked))
+	for format, item := range other.tracked {
+		tracked[format] = item
+	}
+	help := make(map[string]string, len(other.help))
+	for format, h := range other.help {
+		help[format] = h
+	}
+	other.Unlock()
+
+	r.Lock()
+	defer r.Unlock()
+	for format := range tracked {
+		if _, ok := r.tracked[format]; ok {
+			return fmt.Errorf("format string %q already in use", format)
+		}
+	}
+	for format, item := range tracked {
+		r.tracked[format] = item
+	}
+	for format, h := range help {
+		r.help[format] = h
+	}
 	return nil
 }
 
@@ -77,17 +134,222 @@ func (r *Registry) MustAdd(format string, item Iterable) {
 	}
 }
 
-// Each calls the given closure for all metrics.
+// Remove unlinks the Iterable registered under the given format string from
+// this registry. An error is returned if nothing is registered under it.
+func (r *Registry) Remove(format string) error {
+	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.tracked[format]; !ok {
+		return errors.New("format string not in use")
+	}
+	delete(r.tracked, format)
+	delete(r.help, format)
+	return nil
+}
+
+// MustRemove calls Remove and panics on error.
+func (r *Registry) MustRemove(format string) {
+	if err := r.Remove(format); err != nil {
+		panic(fmt.Sprintf("error removing %s: %s", format, err))
+	}
+}
+
+// Len returns the number of Iterables tracked directly by this registry. A
+// registry added via Add counts as a single item.
+func (r *Registry) Len() int {
+	r.Lock()
+	defer r.Unlock()
+	return len(r.tracked)
+}
+
+// SetPrefix sets a prefix which is prepended to the names of all metrics when
+// this registry itself is serialized through MarshalJSON, PrintAsText or
+// PrintAsProto. It does not affect Each, so a registry added to a parent via
+// Add is still named by the parent's format string alone.
+func (r *Registry) SetPrefix(prefix string) {
+	r.Lock()
+	defer r.Unlock()
+	r.prefix = prefix
+}
+
+// SetLabel attaches a label with the given name and value to all metrics when
+// this registry itself is serialized, replacing the value of any label with
+// the same name set previously. For instance, a registry holding the metrics
+// of one tenant of a shared process can be tagged with the tenant's ID without
+// changing the metric definitions. Prometheus exports carry the label on every
+// sample, while MarshalJSON appends it to every key, as in
+// "sql.conns{tenant=5}". Like SetPrefix, it does not affect Each, and the name
+// must not be used as a label by any labeled metric in the registry.
+func (r *Registry) SetLabel(name, value string) {
+	r.Lock()
+	defer r.Unlock()
+	for i := range r.labels {
+		if r.labels[i].name == name {
+			r.labels[i].value = value
+			return
+		}
+	}
+	r.labels = append(r.labels, registryLabel{name: name, value: value})
+}
+
+// labelPairs returns the labels set through SetLabel as prometheus label
+// pairs, along with their rendering as a suffix for JSON keys.
+func (r *Registry) labelPairs() ([]*prometheusgo.LabelPair, string) {
+	r.Lock()
+	defer r.Unlock()
+	if len(r.labels) == 0 {
+		return nil, ""
+	}
+	pairs := make([]*prometheusgo.LabelPair, len(r.labels))
+	parts := make([]string, len(r.labels))
+	for i, l := range r.labels {
+		pairs[i] = &prometheusgo.LabelPair{
+			Name:  proto.String(l.name),
+			Value: proto.String(l.value),
+		}
+		parts[i] = l.name + "=" + l.value
+	}
+	return pairs, "{" + strings.Join(parts, ",") + "}"
+}
+
+// addLabels appends the given label pairs to every metric of the family.
+func addLabels(mf *prometheusgo.MetricFamily, labels []*prometheusgo.LabelPair) {
+	if len(labels) == 0 {
+		return
+	}
+	for _, m := range mf.Metric {
+		m.Label = append(m.Label, labels...)
+	}
+}
+
+// Filter returns a new Registry holding those metrics of this registry whose
+// names (as passed to the closure of Each) match the given regular expression.
+// The returned registry refers to the original metrics, so their values stay
+// live, but metrics added to this registry afterwards do not show up in it.
+func (r *Registry) Filter(expr string) (*Registry, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return r.filter(re.MatchString), nil
+}
+
+func (r *Registry) filter(match func(name string) bool) *Registry {
+	r.Lock()
+	defer r.Unlock()
+	filtered := NewRegistry()
+	filtered.prefix = r.prefix
+	filtered.labels = append([]registryLabel(nil), r.labels...)
+	for format, item := range r.tracked {
+		if sub, ok := item.(*Registry); ok {
+			format := format
+			subFiltered := sub.filter(func(name string) bool {
+				return match(fmt.Sprintf(format, name))
+			})
+			if len(subFiltered.tracked) > 0 {
+				filtered.tracked[format] = subFiltered
+				if help, ok := r.help[format]; ok {
+					filtered.help[format] = help
+				}
+			}
+			continue
+		}
+		if match(format) {
+			filtered.tracked[format] = item
+			if help, ok := r.help[format]; ok {
+				filtered.help[format] = help
+			}
+		}
+	}
+	return filtered
+}
+
+// eachQualified calls the given closure for all metrics, with each name
+// carrying the registry's prefix, along with their help text.
+func (r *Registry) eachQualified(f func(name, help string, val interface{})) {
+	r.Lock()
+	prefix := r.prefix
+	r.Unlock()
+	r.eachWithHelp("", func(name, help string, v interface{}) {
+		f(prefix+name, help, v)
+	})
+}
+
+// Each calls the given closure for all metrics. Items are visited in the
+// sorted order of the format strings they were added under, so that the
+// output of MarshalJSON and PrintAsText is stable.
 func (r *Registry) Each(f func(name string, val interface{})) {
+	r.eachWithHelp("", func(name, _ string, v interface{}) {
+		f(name, v)
+	})
+}
+
+// VisitType is like Each, but only calls the given closure for metrics of the
+// given type, e.g. reflect.TypeOf(&Counter{}). If t is an interface type, all
+// metrics implementing it are visited.
+func (r *Registry) VisitType(t reflect.Type, f func(name string, val interface{})) {
+	r.Each(func(name string, v interface{}) {
+		vt := reflect.TypeOf(v)
+		if vt == t || (t.Kind() == reflect.Interface && vt.Implements(t)) {
+			f(name, v)
+		}
+	})
+}
+
+// WalkChildren calls the given closure for every registry that was added
+// directly to this one, along with the format string it was added under, in
+// sorted order of the format strings. Leaf metrics are skipped; callers that
+// need to traverse a hierarchy of registries can recurse from the closure.
+func (r *Registry) WalkChildren(f func(prefix string, child *Registry)) {
+	r.Lock()
+	formats := make([]string, 0, len(r.tracked))
+	children := make(map[string]*Registry)
+	for format, item := range r.tracked {
+		if sub, ok := item.(*Registry); ok {
+			formats = append(formats, format)
+			children[format] = sub
+		}
+	}
+	r.Unlock()
+	sort.Strings(formats)
+	// The closure is called without holding the lock so that it may access
+	// the registry.
+	for _, format := range formats {
+		f(format, children[format])
+	}
+}
+
+// eachWithHelp is like Each, but also passes the help text of every metric to
+// the closure. Metrics without help text of their own inherit the given help,
+// which is that of the enclosing registry.
+func (r *Registry) eachWithHelp(help string, f func(name, help string, val interface{})) {
 	r.Lock()
 	defer r.Unlock()
-	for format, registry := range r.tracked {
-		registry.Each(func(name string, v interface{}) {
+	formats := make([]string, 0, len(r.tracked))
+	for format := range r.tracked {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	for _, format := range formats {
+		format := format
+		itemHelp, ok := r.help[format]
+		if !ok {
+			itemHelp = help
+		}
+		qualify := func(name string) string {
 			if name == "" {
-				f(format, v)
-			} else {
-				f(fmt.Sprintf(format, name), v)
+				return format
 			}
+			return fmt.Sprintf(format, name)
+		}
+		if sub, ok := r.tracked[format].(*Registry); ok {
+			sub.eachWithHelp(itemHelp, func(name, help string, v interface{}) {
+				f(qualify(name), help, v)
+			})
+			continue
+		}
+		r.tracked[format].Each(func(name string, v interface{}) {
+			f(qualify(name), itemHelp, v)
 		})
 	}
 }
@@ -95,12 +357,116 @@ func (r *Registry) Each(f func(name string, val interface{})) {
 // MarshalJSON marshals to JSON.
 func (r *Registry) MarshalJSON() ([]byte, error) {
 	m := make(map[string]interface{})
-	r.Each(func(name string, v interface{}) {
-		m[name] = v
+	_, suffix := r.labelPairs()
+	r.eachQualified(func(name, _ string, v interface{}) {
+		m[name+suffix] = v
 	})
 	return json.Marshal(m)
 }
 
+// UnmarshalJSON sets the Counters, Gauges and GaugeFloat64s of the registry to
+// the values found under their names in JSON as produced by MarshalJSON. Names
+// which are not registered, and the values of all other metric types, are
+// ignored, so a registry can be restored from a snapshot taken of a different
+// set of metrics.
+func (r *Registry) UnmarshalJSON(data []byte) error {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	_, suffix := r.labelPairs()
+	var ret error
+	r.eachQualified(func(name, _ string, v interface{}) {
+		if ret != nil {
+			return
+		}
+		raw, ok := values[name+suffix]
+		if !ok {
+			return
+		}
+		switch metric := v.(type) {
+		case *Counter:
+			var c int64
+			if ret = json.Unmarshal(raw, &c); ret == nil {
+				metric.Clear()
+				metric.Inc(c)
+			}
+		case *Gauge:
+			var g int64
+			if ret = json.Unmarshal(raw, &g); ret == nil {
+				metric.Update(g)
+			}
+		case *GaugeFloat64:
+			var g float64
+			if ret = json.Unmarshal(raw, &g); ret == nil {
+				metric.Update(g)
+			}
+		}
+		if ret != nil {
+			ret = fmt.Errorf("%s: %s", name, ret)
+		}
+	})
+	return ret
+}
+
+// Snapshot returns the current value of every scalar metric (Counters, Gauges,
+// GaugeFloat64s and Rates) in the registry, keyed by name. Other metric types,
+// such as Histograms, are omitted. The returned map is owned by the caller.
+func (r *Registry) Snapshot() map[string]float64 {
+	m := make(map[string]float64)
+	r.Each(func(name string, v interface{}) {
+		if val, ok := scalarValue(v); ok {
+			m[name] = val
+		}
+	})
+	return m
+}
+
+// Diff returns, for every scalar metric in the registry, the change in its
+// value since other was in the same state. It is shorthand for
+// r.DiffSnapshot(other.Snapshot()).
+func (r *Registry) Diff(other *Registry) map[string]float64 {
+	return r.DiffSnapshot(other.Snapshot())
+}
+
+// DiffSnapshot returns, for every scalar metric in the registry, the
+// difference between its current value and its value in prev, which is
+// typically the result of an earlier call to Snapshot. Metrics missing from
+// prev are treated as having been zero. A Counter whose value decreased has
+// been reset, and its delta is reported as zero rather than negative.
+func (r *Registry) DiffSnapshot(prev map[string]float64) map[string]float64 {
+	m := make(map[string]float64)
+	r.Each(func(name string, v interface{}) {
+		val, ok := scalarValue(v)
+		if !ok {
+			return
+		}
+		delta := val - prev[name]
+		if _, ok := v.(*Counter); ok && delta < 0 {
+			delta = 0
+		}
+		m[name] = delta
+	})
+	return m
+}
+
+// scalarValue extracts the current value of a scalar metric as passed to the
+// closure of Each. The second return value is false for non-scalar metrics.
+func scalarValue(v interface{}) (float64, bool) {
+	switch mtr := v.(type) {
+	case float64:
+		// Rates pass their current value instead of themselves.
+		return mtr, true
+	case *Counter:
+		return float64(mtr.Count()), true
+	case *Gauge:
+		return float64(mtr.Value()), true
+	case *GaugeFloat64:
+		return mtr.Value(), true
+	}
+	return 0, false
+}
+
 var (
 	nameReplaceRE = regexp.MustCompile("[.-]")
 )
@@ -111,18 +477,68 @@ func exportedName(name string) string {
 	return nameReplaceRE.ReplaceAllString(name, "_")
 }
 
+// ToPrometheusMetricFamilies returns all metrics as prometheus MetricFamily
+// messages, in the order in which PrintAsText would output them. An error is
+// returned if two metrics map to the same exported name, since the resulting
+// families could not be told apart by a scraper.
+func (r *Registry) ToPrometheusMetricFamilies() ([]*prometheusgo.MetricFamily, error) {
+	var families []*prometheusgo.MetricFamily
+	seen := map[string]string{}
+	labels, _ := r.labelPairs()
+	var ret error
+	r.eachQualified(func(name, help string, v interface{}) {
+		if ret != nil {
+			return
+		}
+		if metric, ok := v.(PrometheusExportable); ok {
+			exported := exportedName(name)
+			if other, ok := seen[exported]; ok {
+				ret = fmt.Errorf("metrics %q and %q are both exported as %q", other, name, exported)
+				return
+			}
+			seen[exported] = name
+			metricFamily := &prometheusgo.MetricFamily{Name: proto.String(exported)}
+			if help != "" {
+				metricFamily.Help = proto.String(help)
+			}
+			metric.FillPrometheusMetric(metricFamily)
+			if len(metricFamily.Metric) == 0 {
+				// Labeled metrics without any label values in use have
+				// nothing to export yet.
+				return
+			}
+			addLabels(metricFamily, labels)
+			families = append(families, metricFamily)
+		}
+	})
+	if ret != nil {
+		return nil, ret
+	}
+	return families, nil
+}
+
 // PrintAsText outputs all metrics in text format.
 func (r *Registry) PrintAsText(w io.Writer) error {
 	var metricFamily prometheusgo.MetricFamily
+	labels, _ := r.labelPairs()
 	var ret error
-	r.Each(func(name string, v interface{}) {
+	r.eachQualified(func(name, help string, v interface{}) {
 		if ret != nil {
 			return
 		}
 		if metric, ok := v.(PrometheusExportable); ok {
 			metricFamily.Reset()
 			metricFamily.Name = proto.String(exportedName(name))
+			if help != "" {
+				metricFamily.Help = proto.String(help)
+			}
 			metric.FillPrometheusMetric(&metricFamily)
+			if len(metricFamily.Metric) == 0 {
+				// MetricFamilyToText rejects families without metrics, which
+				// is what labeled metrics without any label values produce.
+				return
+			}
+			addLabels(&metricFamily, labels)
 			if _, err := expfmt.MetricFamilyToText(w, &metricFamily); err != nil {
 				ret = err
 			}
@@ -131,6 +547,37 @@ func (r *Registry) PrintAsText(w io.Writer) error {
 	return ret
 }
 
+// PrintAsProto outputs all metrics as length-delimited binary protobuf
+// MetricFamily messages, as expected by prometheus scrapers that negotiate the
+// protobuf exposition format.
+func (r *Registry) PrintAsProto(w io.Writer) error {
+	var metricFamily prometheusgo.MetricFamily
+	labels, _ := r.labelPairs()
+	var ret error
+	enc := expfmt.NewEncoder(w, expfmt.FmtProtoDelim)
+	r.eachQualified(func(name, help string, v interface{}) {
+		if ret != nil {
+			return
+		}
+		if metric, ok := v.(PrometheusExportable); ok {
+			metricFamily.Reset()
+			metricFamily.Name = proto.String(exportedName(name))
+			if help != "" {
+				metricFamily.Help = proto.String(help)
+			}
+			metric.FillPrometheusMetric(&metricFamily)
+			if len(metricFamily.Metric) == 0 {
+				return
+			}
+			addLabels(&metricFamily, labels)
+			if err := enc.Encode(&metricFamily); err != nil {
+				ret = err
+			}
+		}
+	})
+	return ret
+}
+
 // Histogram registers a new windowed HDRHistogram with the given parameters.
 // Data is kept in the active window for approximately the given duration.
 func (r *Registry) Histogram(name string, duration time.Duration, maxVal int64,
@@ -140,6 +587,23 @@ func (r *Registry) Histogram(name string, duration time.Duration, maxVal int64,
 	return h
 }
 
+// GetHistogram returns the Histogram in this registry with the given name. If
+// a Histogram with this name is not present (including if a non-Histogram
+// Iterable is registered with the name), nil is returned.
+func (r *Registry) GetHistogram(name string) *Histogram {
+	r.Lock()
+	defer r.Unlock()
+	iterable, ok := r.tracked[name]
+	if !ok {
+		return nil
+	}
+	histogram, ok := iterable.(*Histogram)
+	if !ok {
+		return nil
+	}
+	return histogram
+}
+
 // Latency is a convenience function which registers histograms with
 // suitable defaults for latency tracking. Values are expressed in ns,
 // are truncated into the interval [0, time.Minute] and are recorded
@@ -149,10 +613,22 @@ func (r *Registry) Histogram(name string, duration time.Duration, maxVal int64,
 // TODO(mrtracy,tschottdorf): need to discuss roll-ups and generally how (and
 // which) information flows between metrics and time series.
 func (r *Registry) Latency(prefix string) Histograms {
+	return r.Histograms(prefix, int64(time.Minute), 2)
+}
+
+// LongDurationWindow is the maximum value of histograms of durations that
+// routinely exceed the minute supported by Latency, such as the time a
+// connection sits idle. Longer durations are truncated to it.
+const LongDurationWindow = time.Hour
+
+// Histograms registers windowed HDRHistograms with the given parameters for
+// each of the DefaultTimeScales. The generated names of the metric will begin
+// with the given prefix.
+func (r *Registry) Histograms(prefix string, maxVal int64, sigFigs int) Histograms {
 	windows := DefaultTimeScales
 	hs := make(Histograms)
 	for _, w := range windows {
-		hs[w] = r.Histogram(prefix+sep+w.name, w.d, int64(time.Minute), 2)
+		hs[w] = r.Histogram(prefix+sep+w.name, w.d, maxVal, sigFigs)
 	}
 	return hs
 }
@@ -164,6 +640,14 @@ func (r *Registry) Counter(name string) *Counter {
 	return c
 }
 
+// LabeledCounter registers a new LabeledCounter with the given name and label
+// names.
+func (r *Registry) LabeledCounter(name string, labelNames ...string) *LabeledCounter {
+	c := NewLabeledCounter(labelNames...)
+	r.MustAdd(name, c)
+	return c
+}
+
 // GetCounter returns the Counter in this registry with the given name. If a
 // Counter with this name is not present (including if a non-Counter Iterable is
 // registered with the name), nil is returned.
@@ -188,6 +672,22 @@ func (r *Registry) Gauge(name string) *Gauge {
 	return g
 }
 
+// GaugeWithPeaks registers a new GaugeWithPeaks with the given name, whose
+// peaks cover approximately the given duration.
+func (r *Registry) GaugeWithPeaks(name string, duration time.Duration) *GaugeWithPeaks {
+	g := NewGaugeWithPeaks(duration)
+	r.MustAdd(name, g)
+	return g
+}
+
+// LabeledGauge registers a new LabeledGauge with the given name and label
+// names.
+func (r *Registry) LabeledGauge(name string, labelNames ...string) *LabeledGauge {
+	g := NewLabeledGauge(labelNames...)
+	r.MustAdd(name, g)
+	return g
+}
+
 // GetGauge returns the Gauge in this registry with the given name. If a Gauge
 // with this name is not present (including if a non-Gauge Iterable is
 // registered with the name), nil is returned.
@@ -212,6 +712,23 @@ func (r *Registry) GaugeFloat64(name string) *GaugeFloat64 {
 	return g
 }
 
+// GetGaugeFloat64 returns the GaugeFloat64 in this registry with the given
+// name. If a GaugeFloat64 with this name is not present (including if a
+// non-GaugeFloat64 Iterable is registered with the name), nil is returned.
+func (r *Registry) GetGaugeFloat64(name string) *GaugeFloat64 {
+	r.Lock()
+	defer r.Unlock()
+	iterable, ok := r.tracked[name]
+	if !ok {
+		return nil
+	}
+	gauge, ok := iterable.(*GaugeFloat64)
+	if !ok {
+		return nil
+	}
+	return gauge
+}
+
 // Rate creates an EWMA rate over the given timescale. The comments on NewRate
 // apply.
 func (r *Registry) Rate(name string, timescale time.Duration) *Rate {
@@ -240,7 +757,13 @@ func (r *Registry) GetRate(name string) *Rate {
 // Rates registers and returns a new Rates instance, which contains a set of EWMA-based rates
 // with generally useful time scales and a cumulative counter.
 func (r *Registry) Rates(prefix string) Rates {
-	scales := DefaultTimeScales
+	return r.RatesWithScales(prefix, DefaultTimeScales)
+}
+
+// RatesWithScales is like Rates, but registers a rate for each of the given
+// time scales instead of the DefaultTimeScales. Time scales at or below 2s are
+// illegal and will cause a panic.
+func (r *Registry) RatesWithScales(prefix string, scales []TimeScale) Rates {
 	es := make(map[TimeScale]*Rate)
 	for _, scale := range scales {
 		es[scale] = r.Rate(prefix+sep+scale.name, scale.d)
@@ -17,8 +17,16 @@
 package metric
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	prometheusgo "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 )
 
 func TestRegistry(t *testing.T) {
@@ -26,12 +34,13 @@ func TestRegistry(t *testing.T) {
 	sub := NewRegistry()
 
 	topGauge := r.Gauge("top.gauge")
-	_ = r.GaugeFloat64("top.floatgauge")
+	topFloatGauge := r.GaugeFloat64("top.floatgauge")
 	topCounter := r.Counter("top.counter")
 	topRate := r.Rate("top.rate", time.Minute)
 	_ = r.Rates("top.rates")
-	_ = r.Histogram("top.hist", time.Minute, 1000, 3)
+	topHist := r.Histogram("top.hist", time.Minute, 1000, 3)
 	_ = r.Latency("top.latency")
+	_ = r.Histograms("top.hists", 100, 1)
 
 	_ = sub.Gauge("gauge")
 	r.MustAdd("bottom.%s#1", sub)
@@ -40,6 +49,12 @@ func TestRegistry(t *testing.T) {
 	}
 	_ = sub.Rates("rates")
 
+	// 5 metrics, 4 from Rates, 3 each from Latency and Histograms and the
+	// sub-registry.
+	if l := r.Len(); l != 16 {
+		t.Errorf("Len returned %d, expected 16", l)
+	}
+
 	expNames := map[string]struct{}{
 		"top.rate":             {},
 		"top.rates-count":      {},
@@ -50,6 +65,9 @@ func TestRegistry(t *testing.T) {
 		"top.latency-1m":       {},
 		"top.latency-10m":      {},
 		"top.latency-1h":       {},
+		"top.hists-1m":         {},
+		"top.hists-10m":        {},
+		"top.hists-1h":         {},
 		"top.gauge":            {},
 		"top.floatgauge":       {},
 		"top.counter":          {},
@@ -81,6 +99,16 @@ func TestRegistry(t *testing.T) {
 		t.Errorf("GetGauge returned non-nil %v of type %T when requesting non-gauge, expected nil", g, g)
 	}
 
+	if g := r.GetGaugeFloat64("top.floatgauge"); g != topFloatGauge {
+		t.Errorf("GetGaugeFloat64 returned %v, expected %v", g, topFloatGauge)
+	}
+	if g := r.GetGaugeFloat64("bad"); g != nil {
+		t.Errorf("GetGaugeFloat64 returned non-nil %v, expected nil", g)
+	}
+	if g := r.GetGaugeFloat64("top.gauge"); g != nil {
+		t.Errorf("GetGaugeFloat64 returned non-nil %v of type %T when requesting non-float-gauge, expected nil", g, g)
+	}
+
 	if c := r.GetCounter("top.counter"); c != topCounter {
 		t.Errorf("GetCounter returned %v, expected %v", c, topCounter)
 	}
@@ -100,4 +128,672 @@ func TestRegistry(t *testing.T) {
 	if r := r.GetRate("top.hist"); r != nil {
 		t.Errorf("GetRate returned non-nil %v of type %T when requesting non-rate, expected nil", r, r)
 	}
+
+	if h := r.GetHistogram("top.hist"); h != topHist {
+		t.Errorf("GetHistogram returned %v, expected %v", h, topHist)
+	}
+	if h := r.GetHistogram("bad"); h != nil {
+		t.Errorf("GetHistogram returned non-nil %v, expected nil", h)
+	}
+	if h := r.GetHistogram("top.gauge"); h != nil {
+		t.Errorf("GetHistogram returned non-nil %v of type %T when requesting non-histogram, expected nil", h, h)
+	}
+}
+
+func TestRegistryNested(t *testing.T) {
+	r := NewRegistry()
+	store := NewRegistry()
+	compaction := NewRegistry()
+
+	_ = compaction.Gauge("bytes")
+	store.MustAdd("compaction.%s", compaction)
+	_ = store.Counter("splits")
+	r.MustAdd("store.%s", store)
+	// Metrics added to a nested registry after it was linked show up too.
+	_ = compaction.Counter("count")
+
+	names := map[string]struct{}{}
+	r.Each(func(name string, _ interface{}) {
+		names[name] = struct{}{}
+	})
+	expNames := []string{"store.compaction.bytes", "store.compaction.count", "store.splits"}
+	if len(names) != len(expNames) {
+		t.Fatalf("expected %v, got %v", expNames, names)
+	}
+	for _, name := range expNames {
+		if _, ok := names[name]; !ok {
+			t.Errorf("expected %s in nested registry", name)
+		}
+	}
+}
+
+func TestRegistryEachSorted(t *testing.T) {
+	r := NewRegistry()
+	sub := NewRegistry()
+	_ = sub.Gauge("b")
+	_ = sub.Gauge("a")
+	_ = r.Counter("zzz")
+	_ = r.Counter("aaa")
+	r.MustAdd("mmm.%s", sub)
+	_ = r.Counter("bbb")
+
+	var names []string
+	r.Each(func(name string, _ interface{}) {
+		names = append(names, name)
+	})
+	expNames := []string{"aaa", "bbb", "mmm.a", "mmm.b", "zzz"}
+	if !reflect.DeepEqual(names, expNames) {
+		t.Errorf("expected %v, got %v", expNames, names)
+	}
+
+	var buf1, buf2 bytes.Buffer
+	if err := r.PrintAsText(&buf1); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.PrintAsText(&buf2); err != nil {
+		t.Fatal(err)
+	}
+	if buf1.String() != buf2.String() {
+		t.Errorf("PrintAsText output is not stable:\n%s\nvs\n%s", buf1.String(), buf2.String())
+	}
+}
+
+func TestRegistryVisitType(t *testing.T) {
+	r := NewRegistry()
+	sub := NewRegistry()
+	_ = sub.Counter("b")
+	_ = sub.Gauge("a")
+	_ = r.Counter("c")
+	_ = r.GaugeFloat64("d")
+	_ = r.Rate("e", time.Minute)
+	r.MustAdd("sub.%s", sub)
+
+	visit := func(typ reflect.Type) []string {
+		var names []string
+		r.VisitType(typ, func(name string, _ interface{}) {
+			names = append(names, name)
+		})
+		return names
+	}
+	for i, tc := range []struct {
+		typ      reflect.Type
+		expNames []string
+	}{
+		{reflect.TypeOf(&Counter{}), []string{"c", "sub.b"}},
+		{reflect.TypeOf(&Gauge{}), []string{"sub.a"}},
+		{reflect.TypeOf(Gauge{}), nil},
+		{reflect.TypeOf((*PrometheusExportable)(nil)).Elem(), []string{"c", "d", "sub.a", "sub.b"}},
+	} {
+		if names := visit(tc.typ); !reflect.DeepEqual(names, tc.expNames) {
+			t.Errorf("%d: expected %v, got %v", i, tc.expNames, names)
+		}
+	}
+}
+
+func TestRegistryWalkChildren(t *testing.T) {
+	r := NewRegistry()
+	sub1 := NewRegistry()
+	sub2 := NewRegistry()
+	nested := NewRegistry()
+	_ = r.Counter("c")
+	_ = sub1.Gauge("g")
+	sub1.MustAdd("nested.%s", nested)
+	r.MustAdd("sub2.%s", sub2)
+	r.MustAdd("sub1.%s", sub1)
+
+	// Recurse, qualifying the format strings of nested registries with those
+	// of their parents.
+	var walked []string
+	var walk func(format string, reg *Registry)
+	walk = func(format string, reg *Registry) {
+		reg.WalkChildren(func(prefix string, child *Registry) {
+			qualified := fmt.Sprintf(format, prefix)
+			walked = append(walked, qualified)
+			walk(qualified, child)
+		})
+	}
+	walk("%s", r)
+	if exp := []string{"sub1.%s", "sub1.nested.%s", "sub2.%s"}; !reflect.DeepEqual(walked, exp) {
+		t.Errorf("expected %v, got %v", exp, walked)
+	}
+}
+
+func TestRegistryRemove(t *testing.T) {
+	r := NewRegistry()
+	_ = r.Counter("counter")
+	sub := NewRegistry()
+	_ = sub.Gauge("gauge")
+	r.MustAdd("sub.%s", sub)
+
+	r.MustRemove("counter")
+	if err := r.Remove("counter"); err == nil {
+		t.Fatalf("expected failure on double-remove")
+	}
+	if c := r.GetCounter("counter"); c != nil {
+		t.Errorf("GetCounter returned non-nil %v after removal", c)
+	}
+	r.MustRemove("sub.%s")
+	if l := r.Len(); l != 0 {
+		t.Errorf("Len returned %d after removing everything, expected 0", l)
+	}
+
+	r.Each(func(name string, _ interface{}) {
+		t.Errorf("unexpected name: %s", name)
+	})
+
+	// A removed name can be registered again.
+	_ = r.Counter("counter")
+}
+
+func TestRegistryRatesWithScales(t *testing.T) {
+	r := NewRegistry()
+	scale5S := NewTimeScale("5s", 5*time.Second)
+	scale1D := NewTimeScale("1d", 24*time.Hour)
+	rates := r.RatesWithScales("rates", []TimeScale{scale5S, scale1D})
+
+	if l := len(rates.Rates); l != 2 {
+		t.Fatalf("expected 2 rates, got %d", l)
+	}
+	for _, name := range []string{"rates-5s", "rates-1d"} {
+		if rate := r.GetRate(name); rate == nil {
+			t.Errorf("GetRate(%q) returned nil", name)
+		}
+	}
+	if r.GetRate("rates-1m") != nil {
+		t.Errorf("unexpected default time scale rate registered")
+	}
+	if c := r.GetCounter("rates-count"); c == nil {
+		t.Errorf("GetCounter returned nil for rates counter")
+	}
+}
+
+func TestRegistryFilter(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("sql.count")
+	_ = r.Gauge("raft.ticks")
+	sub := NewRegistry()
+	g := sub.Gauge("bytes")
+	_ = sub.Gauge("ticks")
+	r.MustAdd("sql.sub.%s", sub)
+
+	if _, err := r.Filter("("); err == nil {
+		t.Fatalf("expected error for invalid regexp")
+	}
+
+	filtered, err := r.Filter("^sql\\.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]struct{}{}
+	filtered.Each(func(name string, _ interface{}) {
+		names[name] = struct{}{}
+	})
+	expNames := []string{"sql.count", "sql.sub.bytes", "sql.sub.ticks"}
+	if len(names) != len(expNames) {
+		t.Fatalf("expected %v, got %v", expNames, names)
+	}
+	for _, name := range expNames {
+		if _, ok := names[name]; !ok {
+			t.Errorf("expected %s in filtered registry", name)
+		}
+	}
+
+	// The filtered registry refers to the original metrics.
+	c.Inc(3)
+	g.Update(7)
+	if fc := filtered.GetCounter("sql.count"); fc != c || fc.Count() != 3 {
+		t.Errorf("filtered counter does not refer to original")
+	}
+	snap := filtered.Snapshot()
+	if v := snap["sql.sub.bytes"]; v != 7 {
+		t.Errorf("expected 7 for sql.sub.bytes, got %f", v)
+	}
+
+	filtered, err = r.Filter("ticks$")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := len(filtered.Snapshot()); l != 2 {
+		t.Errorf("expected 2 metrics ending in ticks, got %d", l)
+	}
+}
+
+func TestRegistryPrintAsProto(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("top.counter").Inc(3)
+	r.Gauge("top.gauge").Update(7)
+	// Rates are not PrometheusExportable and must be skipped.
+	_ = r.Rate("top.rate", time.Minute)
+
+	var buf bytes.Buffer
+	if err := r.PrintAsProto(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	values := map[string]float64{}
+	dec := expfmt.NewDecoder(&buf, expfmt.FmtProtoDelim)
+	for {
+		var mf prometheusgo.MetricFamily
+		if err := dec.Decode(&mf); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		switch mf.GetType() {
+		case prometheusgo.MetricType_COUNTER:
+			values[mf.GetName()] = mf.Metric[0].GetCounter().GetValue()
+		case prometheusgo.MetricType_GAUGE:
+			values[mf.GetName()] = mf.Metric[0].GetGauge().GetValue()
+		default:
+			t.Errorf("unexpected metric type %s for %s", mf.GetType(), mf.GetName())
+		}
+	}
+	expValues := map[string]float64{
+		"top_counter": 3,
+		"top_gauge":   7,
+	}
+	if len(values) != len(expValues) {
+		t.Fatalf("decoded %v, expected %v", values, expValues)
+	}
+	for name, exp := range expValues {
+		if v, ok := values[name]; !ok || v != exp {
+			t.Errorf("%s: decoded %v, expected %v", name, v, exp)
+		}
+	}
+}
+
+func TestRegistryUnusedLabeledMetrics(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("a.counter").Inc(1)
+	_ = r.LabeledCounter("b.labeled.counter", "reason")
+	_ = r.LabeledGauge("c.labeled.gauge", "peer")
+	r.Gauge("d.gauge").Update(2)
+
+	var buf bytes.Buffer
+	if err := r.PrintAsText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	text := buf.String()
+	for _, exp := range []string{"a_counter 1", "d_gauge 2"} {
+		if !strings.Contains(text, exp) {
+			t.Errorf("expected %q in output:\n%s", exp, text)
+		}
+	}
+	for _, unexp := range []string{"b_labeled_counter", "c_labeled_gauge"} {
+		if strings.Contains(text, unexp) {
+			t.Errorf("unexpected %q in output:\n%s", unexp, text)
+		}
+	}
+
+	buf.Reset()
+	if err := r.PrintAsProto(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	families, err := r.ToPrometheusMetricFamilies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 2 {
+		t.Errorf("expected 2 metric families, got %d", len(families))
+	}
+}
+
+func TestRegistryAddWithHelp(t *testing.T) {
+	r := NewRegistry()
+	if err := r.AddWithHelp("documented", "The number of things.", NewCounter()); err != nil {
+		t.Fatal(err)
+	}
+	r.Gauge("undocumented")
+	sub := NewRegistry()
+	sub.Gauge("inherited")
+	if err := sub.AddWithHelp("own", "Its own help.", NewGauge()); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddWithHelp("sub.%s", "Sub metrics.", sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddWithHelp("documented", "Again.", NewCounter()); err == nil {
+		t.Fatal("expected error re-adding documented metric")
+	}
+
+	var buf bytes.Buffer
+	if err := r.PrintAsText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, exp := range []string{
+		"# HELP documented The number of things.\n",
+		"# HELP sub_inherited Sub metrics.\n",
+		"# HELP sub_own Its own help.\n",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected output to contain %q, got:\n%s", exp, out)
+		}
+	}
+	if strings.Contains(out, "# HELP undocumented") {
+		t.Errorf("unexpected help for undocumented metric:\n%s", out)
+	}
+
+	if err := r.Remove("documented"); err != nil {
+		t.Fatal(err)
+	}
+	r.Counter("documented")
+	buf.Reset()
+	if err := r.PrintAsText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "# HELP documented") {
+		t.Errorf("help outlived removed metric:\n%s", buf.String())
+	}
+}
+
+func TestRegistryMerge(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("a")
+	other := NewRegistry()
+	b := other.Gauge("b")
+	if err := other.AddWithHelp("c", "Some help.", NewCounter()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Merge(other); err != nil {
+		t.Fatal(err)
+	}
+	if l := r.Len(); l != 3 {
+		t.Errorf("expected 3 metrics after merge, got %d", l)
+	}
+	if r.tracked["b"] != b {
+		t.Errorf("expected merged registry to share gauge b")
+	}
+	if h := r.help["c"]; h != "Some help." {
+		t.Errorf("expected help to be merged, got %q", h)
+	}
+
+	// A collision leaves the registry untouched.
+	conflicting := NewRegistry()
+	conflicting.Counter("d")
+	conflicting.Counter("a")
+	if err := r.Merge(conflicting); err == nil {
+		t.Fatal("expected error merging conflicting registry")
+	}
+	if _, ok := r.tracked["d"]; ok {
+		t.Errorf("unexpected partial merge of d")
+	}
+	if err := r.Merge(r); err == nil {
+		t.Fatal("expected error merging registry into itself")
+	}
+}
+
+func TestRegistryToPrometheusMetricFamilies(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("top.counter").Inc(3)
+	r.Gauge("top.gauge").Update(7)
+	// Rates are not PrometheusExportable and must be skipped.
+	_ = r.Rate("top.rate", time.Minute)
+	sub := NewRegistry()
+	sub.Gauge("gauge").Update(2)
+	r.MustAdd("sub.%s", sub)
+	r.SetPrefix("cr.")
+
+	families, err := r.ToPrometheusMetricFamilies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, mf := range families {
+		names = append(names, mf.GetName())
+	}
+	expNames := []string{"cr_sub_gauge", "cr_top_counter", "cr_top_gauge"}
+	if !reflect.DeepEqual(names, expNames) {
+		t.Fatalf("got families %v, expected %v", names, expNames)
+	}
+	if v := families[0].Metric[0].GetGauge().GetValue(); v != 2 {
+		t.Errorf("expected sub gauge value 2, got %v", v)
+	}
+	if v := families[1].Metric[0].GetCounter().GetValue(); v != 3 {
+		t.Errorf("expected counter value 3, got %v", v)
+	}
+
+	// Two metrics which are exported under the same name are rejected.
+	r.Gauge("top-gauge")
+	if _, err := r.ToPrometheusMetricFamilies(); err == nil {
+		t.Fatal("expected error for colliding exported names")
+	}
+}
+
+func TestRegistrySetLabel(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("top.counter").Inc(3)
+	r.LabeledCounter("errors", "type").WithLabelValues("syntax").Inc(1)
+	r.SetLabel("tenant", "4")
+	r.SetLabel("tenant", "5")
+	r.SetLabel("zone", "a")
+
+	families, err := r.ToPrometheusMetricFamilies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := map[string][]string{}
+	for _, mf := range families {
+		for _, l := range mf.Metric[0].Label {
+			labels[mf.GetName()] = append(labels[mf.GetName()], l.GetName()+"="+l.GetValue())
+		}
+	}
+	expLabels := map[string][]string{
+		"errors":      {"type=syntax", "tenant=5", "zone=a"},
+		"top_counter": {"tenant=5", "zone=a"},
+	}
+	if !reflect.DeepEqual(labels, expLabels) {
+		t.Errorf("got labels %v, expected %v", labels, expLabels)
+	}
+
+	var buf bytes.Buffer
+	if err := r.PrintAsText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if exp := `top_counter{tenant="5",zone="a"} 3`; !strings.Contains(buf.String(), exp) {
+		t.Errorf("expected text output to contain %q, got:\n%s", exp, buf.String())
+	}
+
+	j, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `"top.counter{tenant=5,zone=a}":3`; !strings.Contains(string(j), exp) {
+		t.Errorf("expected JSON to contain %s, got %s", exp, j)
+	}
+
+	// Labels are not attached when the registry is part of another.
+	parent := NewRegistry()
+	parent.MustAdd("sub.%s", r)
+	families, err = parent.ToPrometheusMetricFamilies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range families {
+		if mf.GetName() == "sub_top_counter" && len(mf.Metric[0].Label) != 0 {
+			t.Errorf("unexpected labels %v on nested registry", mf.Metric[0].Label)
+		}
+	}
+}
+
+func TestRegistryUnmarshalJSON(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("top.counter").Inc(3)
+	r.Gauge("top.gauge").Update(-4)
+	r.GaugeFloat64("top.floatgauge").Update(1.5)
+	r.Histogram("top.hist", time.Minute, 1000, 1).RecordValue(10)
+	sub := NewRegistry()
+	sub.Gauge("gauge").Update(9)
+	r.MustAdd("sub.%s", sub)
+	r.SetPrefix("cr.")
+
+	data, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewRegistry()
+	counter := restored.Counter("top.counter")
+	counter.Inc(100)
+	gauge := restored.Gauge("top.gauge")
+	floatGauge := restored.GaugeFloat64("top.floatgauge")
+	hist := restored.Histogram("top.hist", time.Minute, 1000, 1)
+	restoredSub := NewRegistry()
+	subGauge := restoredSub.Gauge("gauge")
+	restored.MustAdd("sub.%s", restoredSub)
+	restored.SetPrefix("cr.")
+	if err := restored.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if c := counter.Count(); c != 3 {
+		t.Errorf("expected counter 3, got %d", c)
+	}
+	if g := gauge.Value(); g != -4 {
+		t.Errorf("expected gauge -4, got %d", g)
+	}
+	if g := floatGauge.Value(); g != 1.5 {
+		t.Errorf("expected float gauge 1.5, got %f", g)
+	}
+	if g := subGauge.Value(); g != 9 {
+		t.Errorf("expected sub gauge 9, got %d", g)
+	}
+	if c := hist.Current().TotalCount(); c != 0 {
+		t.Errorf("expected histogram to be left untouched, got %d values", c)
+	}
+
+	if err := restored.UnmarshalJSON([]byte(`{"cr.top.gauge": "x"}`)); err == nil {
+		t.Error("expected error for non-numeric gauge value")
+	}
+	if err := restored.UnmarshalJSON([]byte(`{"unknown": 1}`)); err != nil {
+		t.Errorf("unexpected error for unknown metric: %s", err)
+	}
+}
+
+func TestRegistryDuplicateMetric(t *testing.T) {
+	r := NewRegistry()
+	_ = r.Counter("dup")
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected panic when registering a metric under an existing name")
+		}
+	}()
+	_ = r.Gauge("dup")
+}
+
+func TestRegistrySnapshot(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("counter").Inc(3)
+	r.Gauge("gauge").Update(-2)
+	r.GaugeFloat64("floatgauge").Update(1.5)
+	_ = r.Rate("rate", time.Minute)
+	_ = r.Histogram("hist", time.Minute, 1000, 3)
+	sub := NewRegistry()
+	sub.Counter("counter").Inc(4)
+	r.MustAdd("sub.%s", sub)
+
+	snap := r.Snapshot()
+	expSnap := map[string]float64{
+		"counter":     3,
+		"gauge":       -2,
+		"floatgauge":  1.5,
+		"rate":        0,
+		"sub.counter": 4,
+	}
+	if len(snap) != len(expSnap) {
+		t.Fatalf("Snapshot returned %v, expected %v", snap, expSnap)
+	}
+	for name, exp := range expSnap {
+		if v, ok := snap[name]; !ok || v != exp {
+			t.Errorf("%s: Snapshot returned %v, expected %v", name, v, exp)
+		}
+	}
+
+	// The snapshot must not track later updates.
+	r.GetCounter("counter").Inc(1)
+	if v := snap["counter"]; v != 3 {
+		t.Errorf("snapshot changed after update: %v", v)
+	}
+}
+
+func TestRegistrySetPrefix(t *testing.T) {
+	r := NewRegistry()
+	sub := NewRegistry()
+	sub.Counter("counter").Inc(1)
+	sub.SetPrefix("sub.")
+	r.MustAdd("parent.%s", sub)
+
+	// Serializing the sub-registry directly uses its prefix.
+	b, err := sub.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"sub.counter":1}`; string(b) != exp {
+		t.Errorf("MarshalJSON returned %s, expected %s", b, exp)
+	}
+	var buf bytes.Buffer
+	if err := sub.PrintAsText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if exp := "sub_counter 1\n"; !bytes.Contains(buf.Bytes(), []byte(exp)) {
+		t.Errorf("PrintAsText output %q does not contain %q", buf.String(), exp)
+	}
+
+	// Going through the parent only applies the parent's format string.
+	if b, err = r.MarshalJSON(); err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"parent.counter":1}`; string(b) != exp {
+		t.Errorf("MarshalJSON returned %s, expected %s", b, exp)
+	}
+}
+
+func TestRegistryDiff(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("counter")
+	g := r.Gauge("gauge")
+	c.Inc(5)
+	g.Update(10)
+
+	prev := r.Snapshot()
+	c.Inc(3)
+	g.Update(4)
+	r.Counter("new.counter").Inc(2)
+
+	checkDiff := func(diff, expDiff map[string]float64) {
+		if len(diff) != len(expDiff) {
+			t.Fatalf("diff returned %v, expected %v", diff, expDiff)
+		}
+		for name, exp := range expDiff {
+			if v, ok := diff[name]; !ok || v != exp {
+				t.Errorf("%s: diff returned %v, expected %v", name, v, exp)
+			}
+		}
+	}
+	checkDiff(r.DiffSnapshot(prev), map[string]float64{
+		"counter":     3,
+		"gauge":       -6,
+		"new.counter": 2,
+	})
+
+	// A counter which went backwards was reset; its delta is clamped to zero.
+	c.Clear()
+	checkDiff(r.DiffSnapshot(prev), map[string]float64{
+		"counter":     0,
+		"gauge":       -6,
+		"new.counter": 2,
+	})
+
+	other := NewRegistry()
+	other.Counter("counter").Inc(1)
+	other.Gauge("gauge").Update(1)
+	c.Inc(4)
+	checkDiff(r.Diff(other), map[string]float64{
+		"counter":     3,
+		"gauge":       3,
+		"new.counter": 2,
+	})
 }